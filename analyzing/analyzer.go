@@ -0,0 +1,325 @@
+package analyzing
+
+import (
+	"fmt"
+	"monkey/parsing"
+	"slices"
+)
+
+type Options struct {
+	WarnShadowing bool
+}
+
+type declaration struct {
+	identifier *parsing.AstIdentifier
+	constant   bool
+}
+
+type scope struct {
+	declarations map[string]*declaration
+	function     bool
+}
+
+// A closure resolves names when it is called, so an assignment to a constant
+// from an outer function is only reported once no scope in between has
+// declared the same name later on.
+type pendingAssignment struct {
+	identifier  *parsing.AstIdentifier
+	declaration *declaration
+	first       int
+	last        int
+}
+
+// Analyzer walks a parsed program with the same scoping rules as the
+// evaluator and reports problems that are legal but likely mistakes.
+type Analyzer struct {
+	options     Options
+	scopes      []*scope
+	pending     []*pendingAssignment
+	diagnostics []*parsing.Diagnostic
+}
+
+func NewAnalyzer(options Options) *Analyzer {
+	return &Analyzer{
+		options: options,
+	}
+}
+
+func (analyzer *Analyzer) Analyze(ast parsing.AstNode) []*parsing.Diagnostic {
+	analyzer.scopes = nil
+	analyzer.pending = nil
+	analyzer.diagnostics = nil
+	analyzer.analyze(ast)
+	for _, pending := range analyzer.pending {
+		analyzer.reportAssignment(pending.identifier, pending.declaration)
+	}
+	slices.SortStableFunc(analyzer.diagnostics, func(a, b *parsing.Diagnostic) int {
+		return a.Span.Start.Offset - b.Span.Start.Offset
+	})
+	return analyzer.diagnostics
+}
+
+func (analyzer *Analyzer) report(
+	severity parsing.Severity,
+	code parsing.DiagnosticCode,
+	node parsing.AstNode,
+	message string,
+) {
+	analyzer.diagnostics = append(analyzer.diagnostics, &parsing.Diagnostic{
+		Severity: severity,
+		Code:     code,
+		Span:     node.GetSpan(),
+		Message:  message,
+	})
+}
+
+func (analyzer *Analyzer) enterScope(function bool) {
+	analyzer.scopes = append(analyzer.scopes, &scope{
+		declarations: map[string]*declaration{},
+		function:     function,
+	})
+}
+
+// A scope that is left can no longer shadow a constant for pending
+// assignments, once none of their scopes are left the assignment is settled.
+func (analyzer *Analyzer) leaveScope() {
+	index := len(analyzer.scopes) - 1
+	analyzer.scopes = analyzer.scopes[:index]
+
+	pending := analyzer.pending[:0]
+	for _, assignment := range analyzer.pending {
+		assignment.last = min(assignment.last, index-1)
+		if assignment.last < assignment.first {
+			analyzer.reportAssignment(assignment.identifier, assignment.declaration)
+		} else {
+			pending = append(pending, assignment)
+		}
+	}
+	analyzer.pending = pending
+}
+
+// lookup returns the closest declaration of the name and the index of its
+// scope, skipping the innermost skip scopes.
+func (analyzer *Analyzer) lookup(name string, skip int) (*declaration, int) {
+	for i := len(analyzer.scopes) - 1 - skip; i >= 0; i-- {
+		if declaration, ok := analyzer.scopes[i].declarations[name]; ok {
+			return declaration, i
+		}
+	}
+	return nil, -1
+}
+
+func (analyzer *Analyzer) declare(identifier *parsing.AstIdentifier, constant bool) {
+	if len(analyzer.scopes) == 0 {
+		analyzer.enterScope(false)
+	}
+
+	index := len(analyzer.scopes) - 1
+	scope := analyzer.scopes[index]
+	if _, ok := scope.declarations[identifier.Name]; ok {
+		// Redeclaring in the same scope is a runtime error, not shadowing.
+		return
+	}
+
+	if analyzer.options.WarnShadowing {
+		if shadowed, _ := analyzer.lookup(identifier.Name, 1); shadowed != nil {
+			analyzer.report(
+				parsing.SEVERITY_WARNING,
+				parsing.DIAGNOSTIC_SHADOWED_IDENTIFIER,
+				identifier,
+				fmt.Sprintf(
+					"Identifier %q shadows a declaration at %s.",
+					identifier.Name,
+					shadowed.identifier.GetSpan().Start.String(),
+				),
+			)
+		}
+	}
+	scope.declarations[identifier.Name] = &declaration{
+		identifier: identifier,
+		constant:   constant,
+	}
+
+	analyzer.pending = slices.DeleteFunc(analyzer.pending, func(assignment *pendingAssignment) bool {
+		return assignment.identifier.Name == identifier.Name &&
+			assignment.first <= index && index <= assignment.last
+	})
+}
+
+// Assigning to a constant is always a runtime error, so it is reported as an
+// error here regardless of the options.
+func (analyzer *Analyzer) checkAssignment(identifier *parsing.AstIdentifier) {
+	declaration, index := analyzer.lookup(identifier.Name, 0)
+	if declaration == nil || !declaration.constant {
+		return
+	}
+
+	function := -1
+	for i := len(analyzer.scopes) - 1; i > index; i-- {
+		if analyzer.scopes[i].function {
+			function = i
+			break
+		}
+	}
+	if function > index+1 {
+		analyzer.pending = append(analyzer.pending, &pendingAssignment{
+			identifier:  identifier,
+			declaration: declaration,
+			first:       index + 1,
+			last:        function - 1,
+		})
+		return
+	}
+	analyzer.reportAssignment(identifier, declaration)
+}
+
+func (analyzer *Analyzer) reportAssignment(identifier *parsing.AstIdentifier, declaration *declaration) {
+	analyzer.report(
+		parsing.SEVERITY_ERROR,
+		parsing.DIAGNOSTIC_ASSIGNMENT_TO_CONSTANT,
+		identifier,
+		fmt.Sprintf(
+			"Cannot assign to constant %q declared at %s.",
+			identifier.Name,
+			declaration.identifier.GetSpan().Start.String(),
+		),
+	)
+}
+
+func (analyzer *Analyzer) declarePattern(pattern parsing.AstPattern, constant bool) {
+	switch pattern.Type() {
+	case parsing.AST_ARRAY_PATTERN:
+		arrayPattern := pattern.(*parsing.AstArrayPattern)
+		for _, element := range arrayPattern.Elements {
+			analyzer.declare(element, constant)
+		}
+		if arrayPattern.Rest != nil {
+			analyzer.declare(arrayPattern.Rest, constant)
+		}
+	case parsing.AST_HASH_PATTERN:
+		for _, pair := range pattern.(*parsing.AstHashPattern).Pairs {
+			analyzer.declare(pair.Value, constant)
+		}
+	}
+}
+
+func (analyzer *Analyzer) analyzeExpressions(expressions []parsing.AstExpression) {
+	for _, expression := range expressions {
+		analyzer.analyze(expression)
+	}
+}
+
+func (analyzer *Analyzer) analyze(ast parsing.AstNode) {
+	if ast == nil {
+		return
+	}
+
+	switch ast.Type() {
+	case parsing.AST_COMPOUND:
+		analyzer.enterScope(false)
+		for _, statement := range ast.(*parsing.AstCompound).Statements {
+			analyzer.analyze(statement)
+		}
+		analyzer.leaveScope()
+	case parsing.AST_EXPRESSION_STATEMENT:
+		analyzer.analyze(ast.(*parsing.AstExpressionStatement).Expression)
+	case parsing.AST_LET_STATEMENT:
+		letStatement := ast.(*parsing.AstLetStatement)
+		if letStatement.Value != nil {
+			analyzer.analyze(letStatement.Value)
+		}
+		if letStatement.Pattern != nil {
+			analyzer.declarePattern(letStatement.Pattern, letStatement.Constant)
+		} else {
+			analyzer.declare(letStatement.Identifier, letStatement.Constant)
+		}
+	case parsing.AST_RETURN_STATEMENT:
+		returnStatement := ast.(*parsing.AstReturnStatement)
+		if returnStatement.Value != nil {
+			analyzer.analyze(returnStatement.Value)
+		}
+	case parsing.AST_PREFIX_EXPRESSION:
+		analyzer.analyze(ast.(*parsing.AstPrefixExpression).Right)
+	case parsing.AST_INFIX_EXPRESSION:
+		infixExpression := ast.(*parsing.AstInfixExpression)
+		analyzer.analyze(infixExpression.Left)
+		analyzer.analyze(infixExpression.Right)
+	case parsing.AST_LOGICAL_EXPRESSION:
+		logicalExpression := ast.(*parsing.AstLogicalExpression)
+		analyzer.analyze(logicalExpression.Left)
+		analyzer.analyze(logicalExpression.Right)
+	case parsing.AST_FUNCTION_DEFINITION:
+		functionDefinition := ast.(*parsing.AstFunctionDefinition)
+		analyzer.enterScope(true)
+		for _, parameter := range functionDefinition.Parameters {
+			if parameter.Default != nil {
+				analyzer.analyze(parameter.Default)
+			}
+			analyzer.declare(parameter.Identifier, false)
+		}
+		analyzer.analyze(functionDefinition.Body)
+		analyzer.leaveScope()
+	case parsing.AST_FUNCTION_CALL:
+		functionCall := ast.(*parsing.AstFunctionCall)
+		analyzer.analyze(functionCall.Left)
+		analyzer.analyzeExpressions(functionCall.Arguments)
+	case parsing.AST_ARRAY_LITERAL:
+		analyzer.analyzeExpressions(ast.(*parsing.AstArrayLiteral).Items)
+	case parsing.AST_HASH_LITERAL:
+		for _, pair := range ast.(*parsing.AstHashLiteral).Pairs {
+			analyzer.analyze(pair.Key)
+			if pair.Value != nil {
+				analyzer.analyze(pair.Value)
+			}
+		}
+	case parsing.AST_SPREAD:
+		analyzer.analyze(ast.(*parsing.AstSpread).Value)
+	case parsing.AST_INDEX:
+		index := ast.(*parsing.AstIndex)
+		analyzer.analyze(index.Left)
+		analyzer.analyze(index.Index)
+	case parsing.AST_SLICE:
+		slice := ast.(*parsing.AstSlice)
+		analyzer.analyze(slice.Left)
+		if slice.Start != nil {
+			analyzer.analyze(slice.Start)
+		}
+		if slice.End != nil {
+			analyzer.analyze(slice.End)
+		}
+	case parsing.AST_IF_ELSE:
+		ifElse := ast.(*parsing.AstIfElse)
+		analyzer.analyze(ifElse.Condition)
+		analyzer.analyze(ifElse.Then)
+		if ifElse.ElseIf != nil {
+			analyzer.analyze(ifElse.ElseIf)
+		} else if ifElse.Else != nil {
+			analyzer.analyze(ifElse.Else)
+		}
+	case parsing.AST_CONDITIONAL:
+		conditional := ast.(*parsing.AstConditional)
+		analyzer.analyze(conditional.Condition)
+		analyzer.analyze(conditional.Then)
+		analyzer.analyze(conditional.Else)
+	case parsing.AST_ASSIGNMENT:
+		assignment := ast.(*parsing.AstAssignment)
+		analyzer.analyze(assignment.Left)
+		analyzer.analyze(assignment.Value)
+		if assignment.Left.Type() == parsing.AST_IDENTIFIER {
+			analyzer.checkAssignment(assignment.Left.(*parsing.AstIdentifier))
+		}
+	case parsing.AST_TEMPLATE_STRING:
+		analyzer.analyzeExpressions(ast.(*parsing.AstTemplateString).Parts)
+	case parsing.AST_WHILE_STATEMENT:
+		whileStatement := ast.(*parsing.AstWhileStatement)
+		analyzer.analyze(whileStatement.Condition)
+		analyzer.analyze(whileStatement.Body)
+	case parsing.AST_FOR_STATEMENT:
+		forStatement := ast.(*parsing.AstForStatement)
+		analyzer.analyze(forStatement.Iterable)
+		analyzer.enterScope(false)
+		analyzer.declare(forStatement.Identifier, false)
+		analyzer.analyze(forStatement.Body)
+		analyzer.leaveScope()
+	}
+}
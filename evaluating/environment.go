@@ -1,39 +1,70 @@
 package evaluating
 
+// Binding is a single declared name, Constant bindings refuse assignment.
+type Binding struct {
+	Value    Object
+	Constant bool
+}
+
 type Environment struct {
-	Store  map[string]Object
-	Parent *Environment
+	Store   map[string]*Binding
+	Parent  *Environment
+	Runtime *Runtime
 }
 
 func NewEnvironment(parent *Environment) *Environment {
+	runtime := NewRuntime()
+	if parent != nil {
+		runtime = parent.Runtime
+	}
 	return &Environment{
-		Store:  map[string]Object{},
-		Parent: parent,
+		Store:   map[string]*Binding{},
+		Parent:  parent,
+		Runtime: runtime,
 	}
 }
 
-func (environment *Environment) Get(name string) Object {
+// Lookup returns the closest binding of the name, or nil if no environment
+// declares it.
+func (environment *Environment) Lookup(name string) *Binding {
 	currentEnvironment := environment
 	for currentEnvironment != nil {
-		if object, ok := currentEnvironment.Store[name]; ok {
-			return object
+		if binding, ok := currentEnvironment.Store[name]; ok {
+			return binding
 		}
 		currentEnvironment = currentEnvironment.Parent
 	}
 	return nil
 }
 
-func (environment *Environment) Set(name string, value Object) {
-	if environment.Get(name) == nil {
-		environment.Store[name] = value
+func (environment *Environment) Get(name string) Object {
+	binding := environment.Lookup(name)
+	if binding == nil {
+		return nil
 	}
+	return binding.Value
+}
 
-	currentEnvironment := environment
-	_, ok := currentEnvironment.Store[name]
-	for !ok {
-		currentEnvironment = currentEnvironment.Parent
-		_, ok = currentEnvironment.Store[name]
+// Declare binds the name in this environment only, shadowing any binding of
+// the same name in a parent. It fails if the name is already declared here.
+func (environment *Environment) Declare(name string, value Object, constant bool) bool {
+	if _, ok := environment.Store[name]; ok {
+		return false
+	}
+	environment.Store[name] = &Binding{
+		Value:    value,
+		Constant: constant,
 	}
+	return true
+}
 
-	currentEnvironment.Store[name] = value
+// Assign updates the closest binding of the name. It fails if no environment
+// declares the name or if the binding is constant.
+func (environment *Environment) Assign(name string, value Object) bool {
+	binding := environment.Lookup(name)
+	if binding == nil || binding.Constant {
+		return false
+	}
+	binding.Value = value
+	return true
 }
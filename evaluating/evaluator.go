@@ -2,8 +2,13 @@ package evaluating
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"monkey/lexing"
 	"monkey/parsing"
+	"slices"
 	"strings"
+	"unicode/utf8"
 )
 
 func objectError(format string, arguments ...interface{}) Object {
@@ -60,6 +65,40 @@ func objectErrorUnknownPrefixOperator(
 	)
 }
 
+func objectErrorDivisionByZero(
+	left interface{},
+	operator string,
+	right interface{},
+) Object {
+	return objectError("Division by zero: %d %s %d.", left, operator, right)
+}
+
+func objectErrorIntegerOverflow(
+	left interface{},
+	operator string,
+	right interface{},
+) Object {
+	return objectError("Integer overflow: %d %s %d.", left, operator, right)
+}
+
+func objectErrorPrefixIntegerOverflow(
+	operator string,
+	right interface{},
+) Object {
+	return objectError("Integer overflow: %s(%d).", operator, right)
+}
+
+func objectErrorNegativeOperand(
+	left interface{},
+	operator string,
+	right interface{},
+) Object {
+	if operator == "**" {
+		return objectError("Negative exponent: %d %s %d.", left, operator, right)
+	}
+	return objectError("Negative shift count: %d %s %d.", left, operator, right)
+}
+
 func objectErrorIdentifierNotFound(name string) Object {
 	return objectError("Identifier not found: %q.", name)
 }
@@ -68,6 +107,10 @@ func objectErrorIdentifierAlreadyDeclared(name string) Object {
 	return objectError("Identifier already declared in this scope: %q.", name)
 }
 
+func objectErrorAssignmentToConstant(name string) Object {
+	return objectError("Cannot assign to constant: %q.", name)
+}
+
 func objectErrorNotCallable(expression parsing.AstExpression) Object {
 	return objectError("Expression %q is not a callable.", expression.String())
 }
@@ -82,41 +125,122 @@ func objectErrorUnsupportedIndex(indexType ObjectType) Object {
 	)
 }
 
+func objectErrorUnsupportedSequenceIndex(
+	sequenceType ObjectType,
+	indexType ObjectType,
+) Object {
+	return objectError(
+		"Unsupported %s index, must be of type %s, got type %s.",
+		ObjectTypeToString(sequenceType),
+		ObjectTypeToString(OBJECT_INTEGER),
+		ObjectTypeToString(indexType),
+	)
+}
+
+func objectErrorUnsupportedSliceIndex(indexType ObjectType) Object {
+	return objectError(
+		"Unsupported slice index, must be of type %s, got type %s.",
+		ObjectTypeToString(OBJECT_INTEGER),
+		ObjectTypeToString(indexType),
+	)
+}
+
+func objectErrorIndexOutOfRange(index interface{}, length int) Object {
+	return objectError("Index %d out of range for array of length %d.", index, length)
+}
+
+func objectErrorNotSliceable(expression parsing.AstExpression) Object {
+	return objectError("Expression %q is not sliceable.", expression.String())
+}
+
 func objectErrorNotIndexable(expression parsing.AstExpression) Object {
 	return objectError("Expression %q is not a indexable.", expression.String())
 }
 
+func objectErrorCallDepthExceeded(maxCallDepth int) Object {
+	return objectError("Maximum call depth of %d exceeded.", maxCallDepth)
+}
+
+func objectErrorStepLimitExceeded(maxSteps int) Object {
+	return objectError("Maximum of %d steps exceeded.", maxSteps)
+}
+
+func objectErrorBadExpression() Object {
+	return objectError("Cannot evaluate an expression that failed to parse.")
+}
+
+func objectErrorNotDestructurable(expected ObjectType, got ObjectType) Object {
+	return objectError(
+		"Expected %s to destructure, got %s.",
+		ObjectTypeToString(expected),
+		ObjectTypeToString(got),
+	)
+}
+
+func objectErrorWrongNumberOfItems(expected int, got int, rest bool) Object {
+	if rest {
+		return objectError(
+			"Wrong number of items to destructure. Expected at least %d, got %d.",
+			expected,
+			got,
+		)
+	}
+	return objectError(
+		"Wrong number of items to destructure. Expected %d, got %d.",
+		expected,
+		got,
+	)
+}
+
+func objectErrorMissingKey(key string) Object {
+	return objectError("Missing key %q to destructure.", key)
+}
+
 func objectReturnValue(value Object) Object {
 	return &ObjectReturnValue{Value: value}
 }
 
 func objectErrorWrongNumberOfArguments(
-	expected int,
+	signature string,
+	expected string,
 	got int,
 ) Object {
 	return objectError(
-		"Wrong number of arguments. Expected %d, got %d.",
+		"Wrong number of arguments for %s. Expected %s, got %d.",
+		signature,
 		expected,
 		got,
 	)
 }
 
+func objectErrorNotSpreadable(expected ObjectType, got ObjectType) Object {
+	return objectError(
+		"Expected %s to spread, got %s.",
+		ObjectTypeToString(expected),
+		ObjectTypeToString(got),
+	)
+}
+
+func objectErrorNotIterable(expression parsing.AstExpression) Object {
+	return objectError("Expression %q is not iterable.", expression.String())
+}
+
 func objectErrorNotAssignable(expression parsing.AstExpression) Object {
 	return objectError("Expression %q is not assignable.", expression.String())
 }
 
 func InjectBuiltinFunctions(environment *Environment) {
-	environment.Set("len", &ObjectBuiltin{
+	environment.Declare("len", &ObjectBuiltin{
 		Function: func(arguments ...Object) Object {
 			if len(arguments) != 1 {
-				return objectErrorWrongNumberOfArguments(1, len(arguments))
+				return objectErrorWrongNumberOfArguments("len(value)", "1", len(arguments))
 			}
 
 			object := arguments[0]
 
 			switch arguments[0].Type() {
 			case OBJECT_STRING:
-				return &ObjectInteger{Value: int64(len(object.(*ObjectString).Value))}
+				return &ObjectInteger{Value: int64(utf8.RuneCountInString(object.(*ObjectString).Value))}
 			case OBJECT_ARRAY:
 				return &ObjectInteger{Value: int64(len(object.(*ObjectArray).Items))}
 			default:
@@ -127,9 +251,9 @@ func InjectBuiltinFunctions(environment *Environment) {
 				)
 			}
 		},
-	})
+	}, false)
 
-	environment.Set("puts", &ObjectBuiltin{
+	environment.Declare("puts", &ObjectBuiltin{
 		Function: func(arguments ...Object) Object {
 			strs := []string{}
 			for _, argument := range arguments {
@@ -139,7 +263,7 @@ func InjectBuiltinFunctions(environment *Environment) {
 			fmt.Println(strings.Join(strs, " "))
 			return NULL
 		},
-	})
+	}, false)
 }
 func evalCompound(
 	environment *Environment,
@@ -147,10 +271,11 @@ func evalCompound(
 ) Object {
 	compoundEnvironment := NewEnvironment(environment)
 
-	var last Object
+	var last Object = NULL
 	for _, statement := range compound.Statements {
 		last = Eval(compoundEnvironment, statement)
-		if last.Type() == OBJECT_RETURN_VALUE || last.Type() == OBJECT_ERROR {
+		switch last.Type() {
+		case OBJECT_RETURN_VALUE, OBJECT_ERROR, OBJECT_BREAK, OBJECT_CONTINUE:
 			return last
 		}
 	}
@@ -165,6 +290,28 @@ func evalExpressionStatement(
 }
 
 func evalEquality(left Object, operator string, right Object) Object {
+	// Anything can be compared against null, it is only equal to itself.
+	if left.Type() == OBJECT_NULL || right.Type() == OBJECT_NULL {
+		equal := left.Type() == right.Type()
+		if operator == "==" {
+			return &ObjectBoolean{Value: equal}
+		} else {
+			return &ObjectBoolean{Value: !equal}
+		}
+	}
+
+	// Big integers never hold a value that fits in an int64, so mixed
+	// comparisons are only equal through the big integer comparison.
+	if isInteger(left) && isInteger(right) &&
+		(left.Type() == OBJECT_BIG_INTEGER || right.Type() == OBJECT_BIG_INTEGER) {
+		equal := bigInteger(left).Cmp(bigInteger(right)) == 0
+		if operator == "==" {
+			return &ObjectBoolean{Value: equal}
+		} else {
+			return &ObjectBoolean{Value: !equal}
+		}
+	}
+
 	if left.Type() != right.Type() {
 		return objectErrorInfixTypeMismatch(left.Type(), operator, right.Type())
 	}
@@ -185,26 +332,263 @@ func evalEquality(left Object, operator string, right Object) Object {
 		} else {
 			return &ObjectBoolean{Value: leftBoolean != rightBoolean}
 		}
+	} else if left.Type() == OBJECT_STRING && right.Type() == OBJECT_STRING {
+		leftString := left.(*ObjectString).Value
+		rightString := right.(*ObjectString).Value
+		if operator == "==" {
+			return &ObjectBoolean{Value: leftString == rightString}
+		} else {
+			return &ObjectBoolean{Value: leftString != rightString}
+		}
 	} else {
 		return objectErrorUnknownInfixOperator(left.Type(), operator, right.Type())
 	}
 }
 
-func evalIntegerOperation(left Object, operator string, right Object) Object {
-	if left.Type() != OBJECT_INTEGER || right.Type() != OBJECT_INTEGER {
+func multiplyIntegers(left int64, right int64) (int64, bool) {
+	if left == 0 || right == 0 {
+		return 0, true
+	}
+	product := left * right
+	if product/right != left ||
+		(left == -1 && right == math.MinInt64) ||
+		(right == -1 && left == math.MinInt64) {
+		return product, false
+	}
+	return product, true
+}
+
+// The result is wrapped when it does not fit, the second result reports
+// whether it did.
+func powerIntegers(base int64, exponent int64) (int64, bool) {
+	result := int64(1)
+	ok := true
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			product, fits := multiplyIntegers(result, base)
+			result, ok = product, ok && fits
+		}
+		exponent >>= 1
+		if exponent > 0 {
+			square, fits := multiplyIntegers(base, base)
+			base, ok = square, ok && fits
+		}
+	}
+	return result, ok
+}
+
+func shiftIntegerLeft(value int64, count int64) (int64, bool) {
+	if count >= 64 {
+		return 0, value == 0
+	}
+	shifted := value << count
+	return shifted, shifted>>count == value
+}
+
+func isInteger(object Object) bool {
+	return object.Type() == OBJECT_INTEGER || object.Type() == OBJECT_BIG_INTEGER
+}
+
+func bigInteger(object Object) *big.Int {
+	if object.Type() == OBJECT_BIG_INTEGER {
+		return object.(*ObjectBigInteger).Value
+	}
+	return big.NewInt(object.(*ObjectInteger).Value)
+}
+
+func normalizeInteger(value *big.Int) Object {
+	if value.IsInt64() {
+		return &ObjectInteger{Value: value.Int64()}
+	}
+	return &ObjectBigInteger{Value: value}
+}
+
+// Overflowing operations follow the runtime's policy, promotion redoes the
+// operation with big integers.
+func evalIntegerOverflow(
+	runtime *Runtime,
+	left int64,
+	operator string,
+	right int64,
+	wrapped int64,
+) Object {
+	switch runtime.Overflow {
+	case OVERFLOW_WRAP:
+		return &ObjectInteger{Value: wrapped}
+	case OVERFLOW_PROMOTE:
+		return evalBigIntegerOperation(
+			runtime,
+			big.NewInt(left),
+			operator,
+			big.NewInt(right),
+		)
+	default:
+		return objectErrorIntegerOverflow(left, operator, right)
+	}
+}
+
+// Only the operations that can grow a result are checked against the runtime's
+// MaxIntegerBits, shifts and powers before they are computed.
+func evalBigIntegerOperation(
+	runtime *Runtime,
+	left *big.Int,
+	operator string,
+	right *big.Int,
+) Object {
+	limit := int64(runtime.MaxIntegerBits)
+	result := new(big.Int)
+	switch operator {
+	case "+":
+		result.Add(left, right)
+	case "-":
+		result.Sub(left, right)
+	case "*":
+		result.Mul(left, right)
+	case "/", "%":
+		if right.Sign() == 0 {
+			return objectErrorDivisionByZero(left, operator, right)
+		}
+		if operator == "/" {
+			result.Quo(left, right)
+		} else {
+			result.Rem(left, right)
+		}
+	case "**":
+		if right.Sign() < 0 {
+			return objectErrorNegativeOperand(left, operator, right)
+		}
+		// a base of magnitude 2 or more needs at least one bit per unit of
+		// the exponent
+		if left.BitLen() > 1 && (!right.IsInt64() ||
+			right.Int64() > limit ||
+			int64(left.BitLen()-1)*right.Int64() > limit) {
+			return objectErrorIntegerOverflow(left, operator, right)
+		}
+		result.Exp(left, right, nil)
+	case "&":
+		result.And(left, right)
+	case "|":
+		result.Or(left, right)
+	case "^":
+		result.Xor(left, right)
+	case "<<":
+		if right.Sign() < 0 {
+			return objectErrorNegativeOperand(left, operator, right)
+		}
+		if left.Sign() != 0 && (!right.IsInt64() ||
+			right.Int64() > limit ||
+			int64(left.BitLen())+right.Int64() > limit) {
+			return objectErrorIntegerOverflow(left, operator, right)
+		}
+		result.Lsh(left, uint(right.Int64()))
+	case ">>":
+		if right.Sign() < 0 {
+			return objectErrorNegativeOperand(left, operator, right)
+		}
+		// shifting past the last bit leaves 0 or -1 either way
+		count := uint(left.BitLen() + 1)
+		if right.IsInt64() && right.Int64() < int64(count) {
+			count = uint(right.Int64())
+		}
+		result.Rsh(left, count)
+	case ">":
+		return &ObjectBoolean{Value: left.Cmp(right) > 0}
+	case ">=":
+		return &ObjectBoolean{Value: left.Cmp(right) >= 0}
+	case "<":
+		return &ObjectBoolean{Value: left.Cmp(right) < 0}
+	case "<=":
+		return &ObjectBoolean{Value: left.Cmp(right) <= 0}
+	default:
+		return objectErrorUnknownInfixOperator(OBJECT_BIG_INTEGER, operator, OBJECT_BIG_INTEGER)
+	}
+
+	if int64(result.BitLen()) > limit {
+		return objectErrorIntegerOverflow(left, operator, right)
+	}
+	return normalizeInteger(result)
+}
+
+func evalIntegerOperation(
+	runtime *Runtime,
+	left Object,
+	operator string,
+	right Object,
+) Object {
+	if !isInteger(left) || !isInteger(right) {
 		return objectErrorInfixTypeMismatch(left.Type(), operator, right.Type())
 	}
+	if left.Type() == OBJECT_BIG_INTEGER || right.Type() == OBJECT_BIG_INTEGER {
+		return evalBigIntegerOperation(
+			runtime,
+			bigInteger(left),
+			operator,
+			bigInteger(right),
+		)
+	}
 	leftInteger := left.(*ObjectInteger).Value
 	rightInteger := right.(*ObjectInteger).Value
 	switch operator {
 	case "+":
-		return &ObjectInteger{Value: leftInteger + rightInteger}
+		sum := leftInteger + rightInteger
+		if (rightInteger > 0 && sum < leftInteger) || (rightInteger < 0 && sum > leftInteger) {
+			return evalIntegerOverflow(runtime, leftInteger, operator, rightInteger, sum)
+		}
+		return &ObjectInteger{Value: sum}
 	case "-":
-		return &ObjectInteger{Value: leftInteger - rightInteger}
+		difference := leftInteger - rightInteger
+		if (rightInteger > 0 && difference > leftInteger) || (rightInteger < 0 && difference < leftInteger) {
+			return evalIntegerOverflow(runtime, leftInteger, operator, rightInteger, difference)
+		}
+		return &ObjectInteger{Value: difference}
 	case "*":
-		return &ObjectInteger{Value: leftInteger * rightInteger}
+		product, ok := multiplyIntegers(leftInteger, rightInteger)
+		if !ok {
+			return evalIntegerOverflow(runtime, leftInteger, operator, rightInteger, product)
+		}
+		return &ObjectInteger{Value: product}
 	case "/":
+		if rightInteger == 0 {
+			return objectErrorDivisionByZero(leftInteger, operator, rightInteger)
+		}
+		if leftInteger == math.MinInt64 && rightInteger == -1 {
+			return evalIntegerOverflow(runtime, leftInteger, operator, rightInteger, leftInteger)
+		}
 		return &ObjectInteger{Value: leftInteger / rightInteger}
+	case "%":
+		if rightInteger == 0 {
+			return objectErrorDivisionByZero(leftInteger, operator, rightInteger)
+		}
+		return &ObjectInteger{Value: leftInteger % rightInteger}
+	case "**":
+		if rightInteger < 0 {
+			return objectErrorNegativeOperand(leftInteger, operator, rightInteger)
+		}
+		power, ok := powerIntegers(leftInteger, rightInteger)
+		if !ok {
+			return evalIntegerOverflow(runtime, leftInteger, operator, rightInteger, power)
+		}
+		return &ObjectInteger{Value: power}
+	case "&":
+		return &ObjectInteger{Value: leftInteger & rightInteger}
+	case "|":
+		return &ObjectInteger{Value: leftInteger | rightInteger}
+	case "^":
+		return &ObjectInteger{Value: leftInteger ^ rightInteger}
+	case "<<":
+		if rightInteger < 0 {
+			return objectErrorNegativeOperand(leftInteger, operator, rightInteger)
+		}
+		shifted, ok := shiftIntegerLeft(leftInteger, rightInteger)
+		if !ok {
+			return evalIntegerOverflow(runtime, leftInteger, operator, rightInteger, shifted)
+		}
+		return &ObjectInteger{Value: shifted}
+	case ">>":
+		if rightInteger < 0 {
+			return objectErrorNegativeOperand(leftInteger, operator, rightInteger)
+		}
+		return &ObjectInteger{Value: leftInteger >> rightInteger}
 	case ">":
 		return &ObjectBoolean{Value: leftInteger > rightInteger}
 	case ">=":
@@ -224,18 +608,21 @@ func evalStringConcatenation(left Object, right Object) Object {
 	return &ObjectString{Value: leftString + rightString}
 }
 
-func evalInfixOperation(left Object, operator string, right Object) Object {
+func evalInfixOperation(
+	runtime *Runtime,
+	left Object,
+	operator string,
+	right Object,
+) Object {
 	switch operator {
 	case "+":
-		if left.Type() != right.Type() {
-			return objectErrorInfixTypeMismatch(left.Type(), operator, right.Type())
-		}
-		if left.Type() == OBJECT_STRING {
+		if left.Type() == OBJECT_STRING && right.Type() == OBJECT_STRING {
 			return evalStringConcatenation(left, right)
 		}
-		return evalIntegerOperation(left, operator, right)
-	case "-", "*", "/", ">", ">=", "<", "<=":
-		return evalIntegerOperation(left, operator, right)
+		return evalIntegerOperation(runtime, left, operator, right)
+	case "-", "*", "/", "%", "**", "&", "|", "^", "<<", ">>",
+		">", ">=", "<", "<=":
+		return evalIntegerOperation(runtime, left, operator, right)
 	case "==", "!=":
 		return evalEquality(left, operator, right)
 	default:
@@ -248,22 +635,93 @@ func evalInfixExpression(
 	infixExpression *parsing.AstInfixExpression,
 ) Object {
 	left := Eval(environment, infixExpression.Left)
+	if left.Type() == OBJECT_ERROR {
+		return left
+	}
 	right := Eval(environment, infixExpression.Right)
-	return evalInfixOperation(left, infixExpression.Operator, right)
+	if right.Type() == OBJECT_ERROR {
+		return right
+	}
+	return evalInfixOperation(
+		environment.Runtime,
+		left,
+		infixExpression.Operator,
+		right,
+	)
 }
 
-func evalPrefixOperation(operator string, right Object) Object {
+func evalLogicalExpression(
+	environment *Environment,
+	logicalExpression *parsing.AstLogicalExpression,
+) Object {
+	left := Eval(environment, logicalExpression.Left)
+	if left.Type() == OBJECT_ERROR {
+		return left
+	}
+
+	switch logicalExpression.Operator {
+	case "??":
+		if left.Type() != OBJECT_NULL {
+			return left
+		}
+		return Eval(environment, logicalExpression.Right)
+	case "&&":
+		if !left.Truthiness() {
+			return &ObjectBoolean{Value: false}
+		}
+	case "||":
+		if left.Truthiness() {
+			return &ObjectBoolean{Value: true}
+		}
+	}
+
+	right := Eval(environment, logicalExpression.Right)
+	if right.Type() == OBJECT_ERROR {
+		return right
+	}
+	return &ObjectBoolean{Value: right.Truthiness()}
+}
+
+func evalPrefixOperation(
+	runtime *Runtime,
+	operator string,
+	right Object,
+) Object {
 	switch operator {
 	case "!":
 		return &ObjectBoolean{
 			Value: !right.Truthiness(),
 		}
 	case "-":
-		if right.Type() != OBJECT_INTEGER {
+		if !isInteger(right) {
+			return objectErrorPrefixTypeMismatch(operator, right.Type())
+		}
+		if right.Type() == OBJECT_BIG_INTEGER {
+			return normalizeInteger(new(big.Int).Neg(bigInteger(right)))
+		}
+		value := right.(*ObjectInteger).Value
+		if value == math.MinInt64 {
+			switch runtime.Overflow {
+			case OVERFLOW_WRAP:
+				return right
+			case OVERFLOW_PROMOTE:
+				return normalizeInteger(new(big.Int).Neg(bigInteger(right)))
+			default:
+				return objectErrorPrefixIntegerOverflow(operator, value)
+			}
+		}
+		return &ObjectInteger{
+			Value: -value,
+		}
+	case "~":
+		if !isInteger(right) {
 			return objectErrorPrefixTypeMismatch(operator, right.Type())
 		}
+		if right.Type() == OBJECT_BIG_INTEGER {
+			return normalizeInteger(new(big.Int).Not(bigInteger(right)))
+		}
 		return &ObjectInteger{
-			Value: -right.(*ObjectInteger).Value,
+			Value: ^right.(*ObjectInteger).Value,
 		}
 	default:
 		return objectErrorUnknownPrefixOperator(operator, right.Type())
@@ -275,15 +733,31 @@ func evalPrefixExpression(
 	prefixExpression *parsing.AstPrefixExpression,
 ) Object {
 	right := Eval(environment, prefixExpression.Right)
-	return evalPrefixOperation(prefixExpression.Operator, right)
+	if right.Type() == OBJECT_ERROR {
+		return right
+	}
+	return evalPrefixOperation(
+		environment.Runtime,
+		prefixExpression.Operator,
+		right,
+	)
 }
 
 func evalLetStatement(
 	environment *Environment,
 	letStatement *parsing.AstLetStatement,
 ) Object {
-	if environment.Get(letStatement.Identifier.Name) != nil {
-		return objectErrorIdentifierAlreadyDeclared(letStatement.Identifier.Name)
+	if letStatement.Pattern != nil {
+		value := Eval(environment, letStatement.Value)
+		if value.Type() == OBJECT_ERROR {
+			return value
+		}
+		return bindPattern(
+			environment,
+			letStatement.Pattern,
+			value,
+			letStatement.Constant,
+		)
 	}
 
 	var value Object
@@ -291,14 +765,118 @@ func evalLetStatement(
 		value = NULL
 	} else {
 		value = Eval(environment, letStatement.Value)
+		if value.Type() == OBJECT_ERROR {
+			return value
+		}
+	}
+	if function, ok := value.(*ObjectFunction); ok && function.Name == "" {
+		function.Name = letStatement.Identifier.Name
 	}
-	environment.Set(
-		letStatement.Identifier.Name,
+	return bindIdentifier(
+		environment,
+		letStatement.Identifier,
 		value,
+		letStatement.Constant,
 	)
+}
+
+func bindIdentifier(
+	environment *Environment,
+	identifier *parsing.AstIdentifier,
+	value Object,
+	constant bool,
+) Object {
+	if !environment.Declare(identifier.Name, value, constant) {
+		return objectErrorIdentifierAlreadyDeclared(identifier.Name)
+	}
 	return NULL
 }
 
+func bindArrayPattern(
+	environment *Environment,
+	arrayPattern *parsing.AstArrayPattern,
+	value Object,
+	constant bool,
+) Object {
+	if value.Type() != OBJECT_ARRAY {
+		return objectErrorNotDestructurable(OBJECT_ARRAY, value.Type())
+	}
+	items := value.(*ObjectArray).Items
+	count := len(arrayPattern.Elements)
+	rest := arrayPattern.Rest != nil
+	if len(items) < count || (!rest && len(items) > count) {
+		return objectErrorWrongNumberOfItems(count, len(items), rest)
+	}
+
+	for index, element := range arrayPattern.Elements {
+		bound := bindIdentifier(environment, element, items[index], constant)
+		if bound.Type() == OBJECT_ERROR {
+			return bound
+		}
+	}
+	if rest {
+		return bindIdentifier(
+			environment,
+			arrayPattern.Rest,
+			&ObjectArray{Items: slices.Clone(items[count:])},
+			constant,
+		)
+	}
+	return NULL
+}
+
+func bindHashPattern(
+	environment *Environment,
+	hashPattern *parsing.AstHashPattern,
+	value Object,
+	constant bool,
+) Object {
+	if value.Type() != OBJECT_HASH {
+		return objectErrorNotDestructurable(OBJECT_HASH, value.Type())
+	}
+	hash := value.(*ObjectHash)
+
+	for _, pair := range hashPattern.Pairs {
+		item, index := hash.Get(&ObjectString{Value: pair.Key.Value})
+		if index == -1 {
+			return objectErrorMissingKey(pair.Key.Value)
+		}
+		bound := bindIdentifier(environment, pair.Value, item, constant)
+		if bound.Type() == OBJECT_ERROR {
+			return bound
+		}
+	}
+	return NULL
+}
+
+// Patterns bind into the environment of the let statement, shapes have to
+// match exactly unless an array pattern collects the rest.
+func bindPattern(
+	environment *Environment,
+	pattern parsing.AstPattern,
+	value Object,
+	constant bool,
+) Object {
+	switch pattern.Type() {
+	case parsing.AST_ARRAY_PATTERN:
+		return bindArrayPattern(
+			environment,
+			pattern.(*parsing.AstArrayPattern),
+			value,
+			constant,
+		)
+	case parsing.AST_HASH_PATTERN:
+		return bindHashPattern(
+			environment,
+			pattern.(*parsing.AstHashPattern),
+			value,
+			constant,
+		)
+	default:
+		return NULL
+	}
+}
+
 func evalReturnStatement(
 	environment *Environment,
 	returnStatement *parsing.AstReturnStatement,
@@ -306,7 +884,11 @@ func evalReturnStatement(
 	if returnStatement.Value == nil {
 		return objectReturnValue(NULL)
 	}
-	return objectReturnValue(Eval(environment, returnStatement.Value))
+	value := Eval(environment, returnStatement.Value)
+	if value.Type() == OBJECT_ERROR {
+		return value
+	}
+	return objectReturnValue(value)
 }
 
 func evalIdentifier(
@@ -324,10 +906,12 @@ func evalFunctionDefinition(
 	environment *Environment,
 	functionDefinition *parsing.AstFunctionDefinition,
 ) Object {
+	names := map[string]bool{}
 	for _, parameter := range functionDefinition.Parameters {
-		if environment.Get(parameter.Name) != nil {
-			return objectErrorIdentifierAlreadyDeclared(parameter.Name)
+		if names[parameter.Identifier.Name] {
+			return objectErrorIdentifierAlreadyDeclared(parameter.Identifier.Name)
 		}
+		names[parameter.Identifier.Name] = true
 	}
 
 	return &ObjectFunction{
@@ -337,36 +921,117 @@ func evalFunctionDefinition(
 	}
 }
 
+// Spreads are expanded in place, the second result is set instead of the
+// items when an expression fails or a spread value is not an array.
 func evalExpressions(
 	environment *Environment,
 	expressions []parsing.AstExpression,
-) []Object {
-	objects := make([]Object, len(expressions))
-	for i, expression := range expressions {
-		objects[i] = Eval(environment, expression)
+) ([]Object, Object) {
+	objects := []Object{}
+	for _, expression := range expressions {
+		if expression.Type() != parsing.AST_SPREAD {
+			object := Eval(environment, expression)
+			if object.Type() == OBJECT_ERROR {
+				return nil, object
+			}
+			objects = append(objects, object)
+			continue
+		}
+		spread := Eval(environment, expression.(*parsing.AstSpread).Value)
+		if spread.Type() == OBJECT_ERROR {
+			return nil, spread
+		}
+		if spread.Type() != OBJECT_ARRAY {
+			return nil, objectErrorNotSpreadable(OBJECT_ARRAY, spread.Type())
+		}
+		objects = append(objects, spread.(*ObjectArray).Items...)
+	}
+	return objects, nil
+}
+
+// The minimum counts the parameters up to the last one without a default,
+// the maximum is -1 when a rest parameter takes any number of arguments.
+func functionArity(function *ObjectFunction) (int, int) {
+	minimum := 0
+	maximum := len(function.Parameters)
+	for index, parameter := range function.Parameters {
+		if parameter.Rest {
+			maximum = -1
+		} else if parameter.Default == nil {
+			minimum = index + 1
+		}
+	}
+	return minimum, maximum
+}
+
+func checkArity(function *ObjectFunction, arguments []Object) Object {
+	minimum, maximum := functionArity(function)
+	if len(arguments) >= minimum && (maximum == -1 || len(arguments) <= maximum) {
+		return nil
 	}
-	return objects
+
+	expected := fmt.Sprintf("%d", minimum)
+	if maximum == -1 {
+		expected = "at least " + expected
+	} else if maximum != minimum {
+		expected += fmt.Sprintf(" to %d", maximum)
+	}
+	return objectErrorWrongNumberOfArguments(
+		function.Signature(),
+		expected,
+		len(arguments),
+	)
 }
 
+// Defaults are evaluated in the new environment when the call is made, so
+// they can refer to the parameters before them.
 func extendFunctionEnvironment(
 	function *ObjectFunction,
 	arguments []Object,
-) *Environment {
+) (*Environment, Object) {
 	environment := NewEnvironment(function.Environment)
-	for i, parameter := range function.Parameters {
-		environment.Set(parameter.Name, arguments[i])
+	for index, parameter := range function.Parameters {
+		var value Object
+		if parameter.Rest {
+			value = &ObjectArray{Items: slices.Clone(arguments[min(index, len(arguments)):])}
+		} else if index < len(arguments) {
+			value = arguments[index]
+		} else {
+			value = Eval(environment, parameter.Default)
+			if value.Type() == OBJECT_ERROR {
+				return nil, value
+			}
+		}
+		environment.Declare(parameter.Identifier.Name, value, false)
 	}
-	return environment
+	return environment, nil
 }
 
 func applyFunction(
 	function *ObjectFunction,
 	arguments []Object,
+	span lexing.Span,
 ) Object {
-	extendedEnvironment := extendFunctionEnvironment(
+	runtime := function.Environment.Runtime
+	if len(runtime.frames) >= runtime.MaxCallDepth {
+		return objectErrorCallDepthExceeded(runtime.MaxCallDepth)
+	}
+	if !runtime.step() {
+		return objectErrorStepLimitExceeded(runtime.MaxSteps)
+	}
+	runtime.frames = append(runtime.frames, Frame{
+		Name: function.Name,
+		Span: span,
+	})
+	defer func() { runtime.frames = runtime.frames[:len(runtime.frames)-1] }()
+
+	extendedEnvironment, err := extendFunctionEnvironment(
 		function,
 		arguments,
 	)
+	if err != nil {
+		return err
+	}
 	evaluated := Eval(extendedEnvironment, function.Body)
 	if evaluated.Type() == OBJECT_RETURN_VALUE {
 		return evaluated.(*ObjectReturnValue).Value
@@ -378,33 +1043,44 @@ func evalFunctionCall(
 	environment *Environment,
 	functionCall *parsing.AstFunctionCall,
 ) Object {
-	function := Eval(environment, functionCall.Left)
+	function := evalChainLink(environment, functionCall.Left)
+	if function == skippedChain || function.Type() == OBJECT_ERROR {
+		return function
+	}
 	if function.Type() != OBJECT_FUNCTION && function.Type() != OBJECT_BUILTIN {
 		return objectErrorNotCallable(functionCall.Left)
 	}
-	arguments := evalExpressions(
+	arguments, err := evalExpressions(
 		environment,
 		functionCall.Arguments,
 	)
+	if err != nil {
+		return err
+	}
 	if function.Type() == OBJECT_BUILTIN {
 		return function.(*ObjectBuiltin).Function(arguments...)
 	}
-	argumentsLen := len(arguments)
-	parametersLen := len(function.(*ObjectFunction).Parameters)
-	if argumentsLen != parametersLen {
-		return objectErrorWrongNumberOfArguments(parametersLen, argumentsLen)
+	if err := checkArity(function.(*ObjectFunction), arguments); err != nil {
+		return err
 	}
-	return applyFunction(function.(*ObjectFunction), arguments)
+	return applyFunction(
+		function.(*ObjectFunction),
+		arguments,
+		functionCall.Span,
+	)
 }
 
 func evalArrayLiteral(
 	environment *Environment,
 	arrayLiteral *parsing.AstArrayLiteral,
 ) Object {
-	items := evalExpressions(
+	items, err := evalExpressions(
 		environment,
 		arrayLiteral.Items,
 	)
+	if err != nil {
+		return err
+	}
 	return &ObjectArray{Items: items}
 }
 
@@ -417,26 +1093,85 @@ func evalHashLiteral(
 		Values: []Object{},
 	}
 	for _, pair := range hashLiteral.Pairs {
+		if pair.Key.Type() == parsing.AST_SPREAD {
+			spread := Eval(environment, pair.Key.(*parsing.AstSpread).Value)
+			if spread.Type() == OBJECT_ERROR {
+				return spread
+			}
+			if spread.Type() != OBJECT_HASH {
+				return objectErrorNotSpreadable(OBJECT_HASH, spread.Type())
+			}
+			for index, key := range spread.(*ObjectHash).Keys {
+				object.Set(key, spread.(*ObjectHash).Values[index])
+			}
+			continue
+		}
+
 		key := Eval(environment, pair.Key)
-		if key.Type() != OBJECT_STRING &&
-			key.Type() != OBJECT_INTEGER &&
-			key.Type() != OBJECT_BOOLEAN {
+		if key.Type() == OBJECT_ERROR {
+			return key
+		}
+		if !isHashKey(key) {
 			return objectErrorUnsupportedIndex(key.Type())
 		}
-		object.Keys = append(object.Keys, Eval(environment, pair.Key))
-		object.Values = append(object.Values, Eval(environment, pair.Value))
+		value := Eval(environment, pair.Value)
+		if value.Type() == OBJECT_ERROR {
+			return value
+		}
+		object.Set(key, value)
 	}
 	return object
 }
 
+func isHashKey(object Object) bool {
+	return object.Type() == OBJECT_STRING ||
+		isInteger(object) ||
+		object.Type() == OBJECT_BOOLEAN
+}
+
+// Negative indexes count from the end, the second result is false when the
+// index is out of range either way.
+func resolveIndex(index int64, length int) (int, bool) {
+	if index < 0 {
+		index += int64(length)
+	}
+	if index < 0 || index >= int64(length) {
+		return 0, false
+	}
+	return int(index), true
+}
+
 func evalArrayIndex(array *ObjectArray, indexObject Object) Object {
-	index := indexObject.(*ObjectInteger).Value
-	if index < 0 || index >= int64(len(array.Items)) {
+	// no array is long enough for an index outside of the int64 range
+	if indexObject.Type() == OBJECT_BIG_INTEGER {
+		return NULL
+	}
+	if indexObject.Type() != OBJECT_INTEGER {
+		return objectErrorUnsupportedSequenceIndex(OBJECT_ARRAY, indexObject.Type())
+	}
+	index, ok := resolveIndex(indexObject.(*ObjectInteger).Value, len(array.Items))
+	if !ok {
 		return NULL
 	}
 	return array.Items[index]
 }
 
+// Strings are indexed by runes, the result is a string of the single rune.
+func evalStringIndex(text *ObjectString, indexObject Object) Object {
+	if indexObject.Type() == OBJECT_BIG_INTEGER {
+		return NULL
+	}
+	if indexObject.Type() != OBJECT_INTEGER {
+		return objectErrorUnsupportedSequenceIndex(OBJECT_STRING, indexObject.Type())
+	}
+	runes := []rune(text.Value)
+	index, ok := resolveIndex(indexObject.(*ObjectInteger).Value, len(runes))
+	if !ok {
+		return NULL
+	}
+	return &ObjectString{Value: string(runes[index])}
+}
+
 func evalHashIndex(hash *ObjectHash, key Object) Object {
 	object, _ := hash.Get(key)
 	return object
@@ -446,17 +1181,26 @@ func evalIndex(
 	environment *Environment,
 	index *parsing.AstIndex,
 ) Object {
-	left := Eval(environment, index.Left)
+	left := evalChainLink(environment, index.Left)
+	if left == skippedChain || left.Type() == OBJECT_ERROR {
+		return left
+	}
+	if index.Optional && left.Type() == OBJECT_NULL {
+		return skippedChain
+	}
 	key := Eval(environment, index.Index)
-	if key.Type() != OBJECT_STRING &&
-		key.Type() != OBJECT_INTEGER &&
-		key.Type() != OBJECT_BOOLEAN {
+	if key.Type() == OBJECT_ERROR {
+		return key
+	}
+	if !isHashKey(key) {
 		return objectErrorUnsupportedIndex(key.Type())
 	}
 
 	switch left.Type() {
 	case OBJECT_ARRAY:
 		return evalArrayIndex(left.(*ObjectArray), key)
+	case OBJECT_STRING:
+		return evalStringIndex(left.(*ObjectString), key)
 	case OBJECT_HASH:
 		return evalHashIndex(left.(*ObjectHash), key)
 	default:
@@ -464,74 +1208,308 @@ func evalIndex(
 	}
 }
 
+// Slice bounds are clamped like Python's, negative ones count from the end
+// and a missing bound stands for the start or the end.
+func evalSliceBound(
+	environment *Environment,
+	bound parsing.AstExpression,
+	length int,
+	missing int,
+) (int, Object) {
+	if bound == nil {
+		return missing, nil
+	}
+	object := Eval(environment, bound)
+	switch object.Type() {
+	case OBJECT_ERROR:
+		return 0, object
+	case OBJECT_BIG_INTEGER:
+		if object.(*ObjectBigInteger).Value.Sign() < 0 {
+			return 0, nil
+		}
+		return length, nil
+	case OBJECT_INTEGER:
+		index := object.(*ObjectInteger).Value
+		if index < 0 {
+			index += int64(length)
+		}
+		return int(max(0, min(index, int64(length)))), nil
+	default:
+		return 0, objectErrorUnsupportedSliceIndex(object.Type())
+	}
+}
+
+// Slicing always copies, strings are sliced by runes.
+func evalSlice(
+	environment *Environment,
+	slice *parsing.AstSlice,
+) Object {
+	left := evalChainLink(environment, slice.Left)
+	if left == skippedChain || left.Type() == OBJECT_ERROR {
+		return left
+	}
+	if slice.Optional && left.Type() == OBJECT_NULL {
+		return skippedChain
+	}
+
+	var length int
+	var runes []rune
+	switch left.Type() {
+	case OBJECT_ARRAY:
+		length = len(left.(*ObjectArray).Items)
+	case OBJECT_STRING:
+		runes = []rune(left.(*ObjectString).Value)
+		length = len(runes)
+	default:
+		return objectErrorNotSliceable(slice.Left)
+	}
+
+	start, err := evalSliceBound(environment, slice.Start, length, 0)
+	if err != nil {
+		return err
+	}
+	end, err := evalSliceBound(environment, slice.End, length, length)
+	if err != nil {
+		return err
+	}
+	end = max(start, end)
+
+	if left.Type() == OBJECT_STRING {
+		return &ObjectString{Value: string(runes[start:end])}
+	}
+	return &ObjectArray{Items: slices.Clone(left.(*ObjectArray).Items[start:end])}
+}
+
 func evalIfElse(
 	environment *Environment,
 	ifElse *parsing.AstIfElse,
 ) Object {
 	condition := Eval(environment, ifElse.Condition)
+	if condition.Type() == OBJECT_ERROR {
+		return condition
+	}
 	if condition.Truthiness() == true {
 		return Eval(environment, ifElse.Then)
 	}
+	if ifElse.ElseIf != nil {
+		return Eval(environment, ifElse.ElseIf)
+	}
 	if ifElse.Else == nil {
 		return NULL
 	}
 	return Eval(environment, ifElse.Else)
 }
 
-func evalAssignment(
+func evalConditional(
 	environment *Environment,
-	assignment *parsing.AstAssignment,
+	conditional *parsing.AstConditional,
 ) Object {
-	if assignment.Left.Type() == parsing.AST_INDEX {
-		index := assignment.Left.(*parsing.AstIndex)
+	condition := Eval(environment, conditional.Condition)
+	if condition.Type() == OBJECT_ERROR {
+		return condition
+	}
+	if condition.Truthiness() {
+		return Eval(environment, conditional.Then)
+	}
+	return Eval(environment, conditional.Else)
+}
 
-		if index.Left.Type() != parsing.AST_IDENTIFIER {
-			return objectErrorNotIndexable(index.Left)
+func evalTemplateString(
+	environment *Environment,
+	templateString *parsing.AstTemplateString,
+) Object {
+	var builder strings.Builder
+	for _, part := range templateString.Parts {
+		object := Eval(environment, part)
+		if object.Type() == OBJECT_ERROR {
+			return object
 		}
+		builder.WriteString(object.ToString())
+	}
+	return &ObjectString{Value: builder.String()}
+}
 
-		identifier := index.Left.(*parsing.AstIdentifier).Name
+// Evaluates a loop body, reporting whether the loop must stop and with what
+// result. Breaks end the loop, continues move on to the next iteration, and
+// returns and errors leave the loop with the object itself.
+func evalLoopBody(
+	environment *Environment,
+	body *parsing.AstCompound,
+) (Object, bool) {
+	if !environment.Runtime.step() {
+		return objectErrorStepLimitExceeded(environment.Runtime.MaxSteps), true
+	}
+	result := Eval(environment, body)
+	switch result.Type() {
+	case OBJECT_BREAK:
+		return NULL, true
+	case OBJECT_RETURN_VALUE, OBJECT_ERROR:
+		return result, true
+	default:
+		return NULL, false
+	}
+}
 
-		arrayOrHash := environment.Get(identifier)
+func evalWhileStatement(
+	environment *Environment,
+	whileStatement *parsing.AstWhileStatement,
+) Object {
+	for {
+		condition := Eval(environment, whileStatement.Condition)
+		if condition.Type() == OBJECT_ERROR {
+			return condition
+		}
+		if !condition.Truthiness() {
+			return NULL
+		}
+		if result, stop := evalLoopBody(environment, whileStatement.Body); stop {
+			return result
+		}
+	}
+}
 
-		if arrayOrHash == nil {
-			return objectErrorIdentifierNotFound(identifier)
+func iterableItems(iterable Object) ([]Object, bool) {
+	switch iterable.Type() {
+	case OBJECT_ARRAY:
+		return slices.Clone(iterable.(*ObjectArray).Items), true
+	case OBJECT_HASH:
+		return slices.Clone(iterable.(*ObjectHash).Keys), true
+	case OBJECT_STRING:
+		items := []Object{}
+		for _, character := range iterable.(*ObjectString).Value {
+			items = append(items, &ObjectString{Value: string(character)})
+		}
+		return items, true
+	default:
+		return nil, false
+	}
+}
+
+func evalForStatement(
+	environment *Environment,
+	forStatement *parsing.AstForStatement,
+) Object {
+	iterable := Eval(environment, forStatement.Iterable)
+	if iterable.Type() == OBJECT_ERROR {
+		return iterable
+	}
+	items, ok := iterableItems(iterable)
+	if !ok {
+		return objectErrorNotIterable(forStatement.Iterable)
+	}
+
+	for _, item := range items {
+		iterationEnvironment := NewEnvironment(environment)
+		iterationEnvironment.Declare(forStatement.Identifier.Name, item, false)
+		if result, stop := evalLoopBody(iterationEnvironment, forStatement.Body); stop {
+			return result
+		}
+	}
+	return NULL
+}
+
+func evalAssignment(
+	environment *Environment,
+	assignment *parsing.AstAssignment,
+) Object {
+	if assignment.Left.Type() == parsing.AST_INDEX {
+		index := assignment.Left.(*parsing.AstIndex)
+
+		arrayOrHash := Eval(environment, index.Left)
+		if arrayOrHash.Type() == OBJECT_ERROR {
+			return arrayOrHash
 		}
 
 		indexObject := Eval(environment, index.Index)
+		if indexObject.Type() == OBJECT_ERROR {
+			return indexObject
+		}
 		value := Eval(environment, assignment.Value)
-
-		if arrayOrHash.Type() == OBJECT_HASH {
-			hash := arrayOrHash.(*ObjectHash)
-			hash.Set(indexObject, value)
+		if value.Type() == OBJECT_ERROR {
 			return value
 		}
 
-		if arrayOrHash.Type() == OBJECT_ARRAY {
+		switch arrayOrHash.Type() {
+		case OBJECT_HASH:
+			if !isHashKey(indexObject) {
+				return objectErrorUnsupportedIndex(indexObject.Type())
+			}
+			arrayOrHash.(*ObjectHash).Set(indexObject, value)
+			return value
+		case OBJECT_ARRAY:
 			array := arrayOrHash.(*ObjectArray)
-			array.Items[indexObject.(*ObjectInteger).Value] = value
+			if indexObject.Type() == OBJECT_BIG_INTEGER {
+				return objectErrorIndexOutOfRange(indexObject.(*ObjectBigInteger).Value, len(array.Items))
+			}
+			if indexObject.Type() != OBJECT_INTEGER {
+				return objectErrorUnsupportedSequenceIndex(OBJECT_ARRAY, indexObject.Type())
+			}
+			position, ok := resolveIndex(indexObject.(*ObjectInteger).Value, len(array.Items))
+			if !ok {
+				return objectErrorIndexOutOfRange(indexObject.(*ObjectInteger).Value, len(array.Items))
+			}
+			array.Items[position] = value
 			return value
+		default:
+			return objectErrorNotAssignable(assignment.Left)
 		}
-
-		return NULL
 	}
 
 	if assignment.Left.Type() == parsing.AST_IDENTIFIER {
 		identifier := assignment.Left.(*parsing.AstIdentifier).Name
 
-		if environment.Get(identifier) == nil {
+		binding := environment.Lookup(identifier)
+		if binding == nil {
 			return objectErrorIdentifierNotFound(identifier)
 		}
+		if binding.Constant {
+			return objectErrorAssignmentToConstant(identifier)
+		}
 
 		value := Eval(environment, assignment.Value)
-		environment.Set(identifier, value)
+		if value.Type() == OBJECT_ERROR {
+			return value
+		}
+		environment.Assign(identifier, value)
 
 		return value
 	}
 
-	return objectErrorNotIndexable(assignment.Left)
+	return objectErrorNotAssignable(assignment.Left)
 }
 
+// An optional link that meets null returns skippedChain, so the indexes,
+// slices and calls after it in the same chain are skipped as well. It only
+// travels along the chain, Eval turns it into NULL where the chain ends. It
+// has its own type because pointers to empty structs may compare equal.
+type skippedChainObject struct {
+	ObjectNull
+}
+
+var skippedChain Object = &skippedChainObject{}
+
+// Eval evaluates a node, errors are located at the innermost node they were
+// raised at, together with the calls active at that point.
 func Eval(environment *Environment, ast parsing.AstNode) Object {
+	object := evalChainLink(environment, ast)
+	if object == skippedChain {
+		return NULL
+	}
+	return object
+}
+
+// evalChainLink evaluates the left side of an index, slice or call, it is
+// Eval without ending the chain.
+func evalChainLink(environment *Environment, ast parsing.AstNode) Object {
+	object := evalNode(environment, ast)
+	if error, ok := object.(*ObjectError); ok && error.Trace == nil {
+		error.Span = ast.GetSpan()
+		error.Trace = environment.Runtime.Trace()
+	}
+	return object
+}
+
+func evalNode(environment *Environment, ast parsing.AstNode) Object {
 	switch ast.Type() {
 	case parsing.AST_COMPOUND:
 		return evalCompound(environment, ast.(*parsing.AstCompound))
@@ -558,6 +1536,8 @@ func Eval(environment *Environment, ast parsing.AstNode) Object {
 		return &ObjectBoolean{
 			Value: ast.(*parsing.AstBooleanLiteral).Value,
 		}
+	case parsing.AST_NULL_LITERAL:
+		return NULL
 	case parsing.AST_PREFIX_EXPRESSION:
 		return evalPrefixExpression(
 			environment,
@@ -597,6 +1577,11 @@ func Eval(environment *Environment, ast parsing.AstNode) Object {
 			environment,
 			ast.(*parsing.AstHashLiteral),
 		)
+	case parsing.AST_SLICE:
+		return evalSlice(
+			environment,
+			ast.(*parsing.AstSlice),
+		)
 	case parsing.AST_INDEX:
 		return evalIndex(
 			environment,
@@ -612,6 +1597,37 @@ func Eval(environment *Environment, ast parsing.AstNode) Object {
 			environment,
 			ast.(*parsing.AstAssignment),
 		)
+	case parsing.AST_TEMPLATE_STRING:
+		return evalTemplateString(
+			environment,
+			ast.(*parsing.AstTemplateString),
+		)
+	case parsing.AST_LOGICAL_EXPRESSION:
+		return evalLogicalExpression(
+			environment,
+			ast.(*parsing.AstLogicalExpression),
+		)
+	case parsing.AST_WHILE_STATEMENT:
+		return evalWhileStatement(
+			environment,
+			ast.(*parsing.AstWhileStatement),
+		)
+	case parsing.AST_FOR_STATEMENT:
+		return evalForStatement(
+			environment,
+			ast.(*parsing.AstForStatement),
+		)
+	case parsing.AST_CONDITIONAL:
+		return evalConditional(
+			environment,
+			ast.(*parsing.AstConditional),
+		)
+	case parsing.AST_BREAK_STATEMENT:
+		return BREAK
+	case parsing.AST_CONTINUE_STATEMENT:
+		return CONTINUE
+	case parsing.AST_BAD_EXPRESSION:
+		return objectErrorBadExpression()
 	default:
 		// the switch will be exaustive so this should never happen
 		return nil
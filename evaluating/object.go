@@ -2,13 +2,18 @@ package evaluating
 
 import (
 	"fmt"
+	"math/big"
+	"monkey/lexing"
 	"monkey/parsing"
+	"slices"
+	"strings"
 )
 
 const (
 	_ = iota
 	OBJECT_ERROR
 	OBJECT_INTEGER
+	OBJECT_BIG_INTEGER
 	OBJECT_BOOLEAN
 	OBJECT_NULL
 	OBJECT_ARRAY
@@ -17,6 +22,8 @@ const (
 	OBJECT_STRING
 	OBJECT_RETURN_VALUE
 	OBJECT_BUILTIN
+	OBJECT_BREAK
+	OBJECT_CONTINUE
 )
 
 type ObjectType int
@@ -25,7 +32,7 @@ func ObjectTypeToString(objectType ObjectType) string {
 	switch objectType {
 	case OBJECT_ERROR:
 		return "error"
-	case OBJECT_INTEGER:
+	case OBJECT_INTEGER, OBJECT_BIG_INTEGER:
 		return "integer"
 	case OBJECT_BOOLEAN:
 		return "boolean"
@@ -43,6 +50,10 @@ func ObjectTypeToString(objectType ObjectType) string {
 		return "return value"
 	case OBJECT_BUILTIN:
 		return "builtin"
+	case OBJECT_BREAK:
+		return "break"
+	case OBJECT_CONTINUE:
+		return "continue"
 	default:
 		return "unknown"
 	}
@@ -55,8 +66,13 @@ type Object interface {
 	Truthiness() bool
 }
 
+// Span is the node the error was raised at and Trace the calls that were
+// active at that point, outermost first. Both are set by Eval, Trace is nil
+// until then.
 type ObjectError struct {
 	Message string
+	Span    lexing.Span
+	Trace   []Frame
 }
 
 func (error *ObjectError) Type() ObjectType {
@@ -72,6 +88,51 @@ func (error *ObjectError) Truthiness() bool {
 	return true
 }
 
+// StackTrace formats the error like a Python traceback, each line names the
+// function the position belongs to. Consecutive identical lines, as left by
+// deep recursion, are collapsed.
+func (error *ObjectError) StackTrace() string {
+	positions := []lexing.Position{}
+	for _, frame := range error.Trace {
+		positions = append(positions, frame.Span.Start)
+	}
+	positions = append(positions, error.Span.Start)
+
+	lines := []string{"Traceback (most recent call last):"}
+	previous := ""
+	repeated := 0
+	flush := func() {
+		if repeated == 1 {
+			lines = append(lines, "  [previous line repeated 1 more time]")
+		} else if repeated > 1 {
+			lines = append(lines, fmt.Sprintf("  [previous line repeated %d more times]", repeated))
+		}
+		repeated = 0
+	}
+	for index, position := range positions {
+		function := "<main>"
+		if index > 0 {
+			function = error.Trace[index-1].Name
+			if function == "" {
+				function = "<anonymous>"
+			}
+		}
+
+		line := fmt.Sprintf("  at %s in %s", position.String(), function)
+		if line == previous {
+			repeated += 1
+			continue
+		}
+		flush()
+		lines = append(lines, line)
+		previous = line
+	}
+	flush()
+
+	lines = append(lines, "Error: "+error.Message)
+	return strings.Join(lines, "\n")
+}
+
 type ObjectNull struct{}
 
 func (null *ObjectNull) Type() ObjectType {
@@ -109,6 +170,25 @@ func (integer *ObjectInteger) Truthiness() bool {
 	return true
 }
 
+// Big integers only hold values outside of the int64 range, results that fit
+// are always turned back into an ObjectInteger.
+type ObjectBigInteger struct {
+	Value *big.Int
+}
+
+func (integer *ObjectBigInteger) Type() ObjectType {
+	return OBJECT_BIG_INTEGER
+}
+func (integer *ObjectBigInteger) Inspect() string {
+	return integer.Value.String()
+}
+func (integer *ObjectBigInteger) ToString() string {
+	return integer.Inspect()
+}
+func (integer *ObjectBigInteger) Truthiness() bool {
+	return integer.Value.Sign() != 0
+}
+
 type ObjectBoolean struct {
 	Value bool
 }
@@ -126,8 +206,11 @@ func (boolean *ObjectBoolean) Truthiness() bool {
 	return boolean.Value
 }
 
+// The name is set when a function literal is first bound with let, it stays
+// empty for functions that are only passed around.
 type ObjectFunction struct {
-	Parameters  []*parsing.AstIdentifier
+	Name        string
+	Parameters  []*parsing.AstParameter
 	Body        *parsing.AstCompound
 	Environment *Environment
 }
@@ -152,6 +235,24 @@ func (function *ObjectFunction) Inspect() string {
 func (function *ObjectFunction) ToString() string {
 	return "<fn>"
 }
+func (function *ObjectFunction) Signature() string {
+	text := function.Name
+	if text == "" {
+		text = "fn "
+	}
+	text += "("
+
+	for index, parameter := range function.Parameters {
+		text += parameter.String()
+		if index < len(function.Parameters)-1 {
+			text += ", "
+		}
+	}
+
+	text += ")"
+
+	return text
+}
 func (function *ObjectFunction) Truthiness() bool {
 	return true
 }
@@ -164,15 +265,7 @@ func (array *ObjectArray) Type() ObjectType {
 	return OBJECT_ARRAY
 }
 func (array *ObjectArray) Inspect() string {
-	text := "["
-	for index, element := range array.Items {
-		text += element.Inspect()
-		if index < len(array.Items)-1 {
-			text += ", "
-		}
-	}
-	text += "]"
-	return text
+	return inspect(array, nil)
 }
 func (array *ObjectArray) ToString() string {
 	return array.Inspect()
@@ -189,7 +282,7 @@ func (string *ObjectString) Type() ObjectType {
 	return OBJECT_STRING
 }
 func (string *ObjectString) Inspect() string {
-	return fmt.Sprintf("%q", string.Value)
+	return lexing.Quote(string.Value)
 }
 func (string *ObjectString) ToString() string {
 	return string.Value
@@ -210,15 +303,7 @@ func (hash *ObjectHash) Type() ObjectType {
 	return OBJECT_HASH
 }
 func (hash *ObjectHash) Inspect() string {
-	text := "{"
-	for index, key := range hash.Keys {
-		text += key.Inspect() + ": " + hash.Values[index].Inspect()
-		if index < len(hash.Keys)-1 {
-			text += ", "
-		}
-	}
-	text += "}"
-	return text
+	return inspect(hash, nil)
 }
 func (hash *ObjectHash) ToString() string {
 	return hash.Inspect()
@@ -240,6 +325,10 @@ func (hash *ObjectHash) Get(key Object) (Object, int) {
 			if hashKey.(*ObjectInteger).Value == key.(*ObjectInteger).Value {
 				return hash.Values[index], index
 			}
+		case OBJECT_BIG_INTEGER:
+			if hashKey.(*ObjectBigInteger).Value.Cmp(key.(*ObjectBigInteger).Value) == 0 {
+				return hash.Values[index], index
+			}
 		case OBJECT_BOOLEAN:
 			if hashKey.(*ObjectBoolean).Value == key.(*ObjectBoolean).Value {
 				return hash.Values[index], index
@@ -261,6 +350,45 @@ func (hash *ObjectHash) Set(key Object, value Object) {
 	}
 }
 
+// inspect prints arrays and hashes that contain themselves as [...] or {...}
+// instead of recursing forever, visiting holds the containers being printed.
+func inspect(object Object, visiting []Object) string {
+	switch object.Type() {
+	case OBJECT_ARRAY:
+		if slices.Contains(visiting, object) {
+			return "[...]"
+		}
+		visiting = append(visiting, object)
+		array := object.(*ObjectArray)
+		text := "["
+		for index, element := range array.Items {
+			text += inspect(element, visiting)
+			if index < len(array.Items)-1 {
+				text += ", "
+			}
+		}
+		text += "]"
+		return text
+	case OBJECT_HASH:
+		if slices.Contains(visiting, object) {
+			return "{...}"
+		}
+		visiting = append(visiting, object)
+		hash := object.(*ObjectHash)
+		text := "{"
+		for index, key := range hash.Keys {
+			text += inspect(key, visiting) + ": " + inspect(hash.Values[index], visiting)
+			if index < len(hash.Keys)-1 {
+				text += ", "
+			}
+		}
+		text += "}"
+		return text
+	default:
+		return object.Inspect()
+	}
+}
+
 type ObjectReturnValue struct {
 	Value Object
 }
@@ -296,3 +424,37 @@ func (builtin *ObjectBuiltin) ToString() string {
 func (builtin *ObjectBuiltin) Truthiness() bool {
 	return true
 }
+
+type ObjectBreak struct{}
+
+func (breakObject *ObjectBreak) Type() ObjectType {
+	return OBJECT_BREAK
+}
+func (breakObject *ObjectBreak) Inspect() string {
+	return "break"
+}
+func (breakObject *ObjectBreak) ToString() string {
+	return breakObject.Inspect()
+}
+func (breakObject *ObjectBreak) Truthiness() bool {
+	return false
+}
+
+var BREAK = &ObjectBreak{}
+
+type ObjectContinue struct{}
+
+func (continueObject *ObjectContinue) Type() ObjectType {
+	return OBJECT_CONTINUE
+}
+func (continueObject *ObjectContinue) Inspect() string {
+	return "continue"
+}
+func (continueObject *ObjectContinue) ToString() string {
+	return continueObject.Inspect()
+}
+func (continueObject *ObjectContinue) Truthiness() bool {
+	return false
+}
+
+var CONTINUE = &ObjectContinue{}
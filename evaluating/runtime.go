@@ -0,0 +1,59 @@
+package evaluating
+
+import "monkey/lexing"
+
+const DEFAULT_MAX_CALL_DEPTH = 1000
+const DEFAULT_MAX_INTEGER_BITS = 1 << 16
+
+const (
+	_ = iota
+	OVERFLOW_ERROR
+	OVERFLOW_WRAP
+	OVERFLOW_PROMOTE
+)
+
+// OverflowPolicy decides what integer operations do when a result does not
+// fit in 64 bits. Promoted results are arbitrary precision integers, bounded
+// by the runtime's MaxIntegerBits.
+type OverflowPolicy int
+
+// Frame is one active function call, Name is empty for anonymous functions
+// and Span is the span of the call expression.
+type Frame struct {
+	Name string
+	Span lexing.Span
+}
+
+// The runtime holds the state shared by every environment of one program,
+// environments created from a parent share the parent's runtime. MaxSteps
+// bounds the loop iterations and function calls of the program, zero means
+// no limit.
+type Runtime struct {
+	MaxCallDepth   int
+	MaxIntegerBits int
+	MaxSteps       int
+	Overflow       OverflowPolicy
+	frames         []Frame
+	steps          int
+}
+
+func NewRuntime() *Runtime {
+	return &Runtime{
+		MaxCallDepth:   DEFAULT_MAX_CALL_DEPTH,
+		MaxIntegerBits: DEFAULT_MAX_INTEGER_BITS,
+		Overflow:       OVERFLOW_ERROR,
+	}
+}
+
+// step counts one loop iteration or function call, it fails once the program
+// has used up its MaxSteps.
+func (runtime *Runtime) step() bool {
+	runtime.steps += 1
+	return runtime.MaxSteps == 0 || runtime.steps <= runtime.MaxSteps
+}
+
+// Trace returns a copy of the active frames, outermost first. It is never nil,
+// even outside of any call.
+func (runtime *Runtime) Trace() []Frame {
+	return append([]Frame{}, runtime.frames...)
+}
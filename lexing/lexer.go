@@ -1,58 +1,228 @@
 package lexing
 
-import "bytes"
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
-func isDigit(character byte) bool {
+func isDigit(character rune) bool {
 	return character >= '0' && character <= '9'
 }
 
-func isAlphabetic(character byte) bool {
-	return (character >= 'A' && character <= 'Z') ||
-		(character >= 'a' && character <= 'z') ||
-		character == '_'
+func isAlphabetic(character rune) bool {
+	return unicode.IsLetter(character) || character == '_'
 }
 
-func isAlphanumeric(character byte) bool {
-	return isDigit(character) || isAlphabetic(character)
+func isAlphanumeric(character rune) bool {
+	return isAlphabetic(character) ||
+		unicode.IsDigit(character) ||
+		unicode.In(character, unicode.Mn, unicode.Mc)
 }
 
 type Lexer struct {
-	content  string
-	position int
-	current  byte
+	file       string
+	reader     *bufio.Reader
+	readError  error
+	readFailed bool
+	// open braces inside the embedded expression of each template string
+	templates  []int
+	text       bytes.Buffer
+	position   int
+	current    rune
+	width      int
+	line       int
+	column     int
+	byteColumn int
+	runeOffset int
 }
 
 func NewLexer(content string) *Lexer {
-	return &Lexer{
-		content:  content,
-		position: 0,
-		current:  content[0],
+	return NewFileLexer("", content)
+}
+
+func NewFileLexer(file string, content string) *Lexer {
+	return NewReaderLexer(file, strings.NewReader(content))
+}
+
+// The reader is consumed lazily as tokens are requested, only the text of the
+// token being collected is kept in memory.
+func NewReaderLexer(file string, reader io.Reader) *Lexer {
+	lexer := &Lexer{
+		file:       file,
+		reader:     bufio.NewReader(reader),
+		position:   0,
+		line:       1,
+		column:     1,
+		byteColumn: 1,
+		runeOffset: 0,
+	}
+	lexer.current, lexer.width = lexer.characterAt(0)
+	return lexer
+}
+
+// The character at the given byte distance from the current one, without
+// consuming anything from the reader.
+func (lexer *Lexer) characterAt(distance int) (rune, int) {
+	buffered, err := lexer.reader.Peek(distance + utf8.UTFMax)
+	if len(buffered) <= distance {
+		if err != nil && err != io.EOF && lexer.readError == nil {
+			lexer.readError = err
+		}
+		return '\x00', 0
+	}
+	return utf8.DecodeRune(buffered[distance:])
+}
+
+func (lexer *Lexer) atEnd() bool {
+	return lexer.width == 0
+}
+
+// An invalid byte decodes as utf8.RuneError with a width of one, which is
+// how it is told apart from a literal U+FFFD in the source.
+func (lexer *Lexer) atInvalidEncoding() bool {
+	return lexer.current == utf8.RuneError && lexer.width == 1
+}
+
+func (lexer *Lexer) mark() int {
+	return lexer.text.Len()
+}
+
+func (lexer *Lexer) recorded(mark int) string {
+	return string(lexer.text.Bytes()[mark:])
+}
+
+func (lexer *Lexer) location() Position {
+	return Position{
+		File:       lexer.file,
+		Line:       lexer.line,
+		Column:     lexer.column,
+		ByteColumn: lexer.byteColumn,
+		Offset:     lexer.position,
+		RuneOffset: lexer.runeOffset,
 	}
 }
 
 func (lexer *Lexer) advance() {
-	if lexer.position+1 >= len(lexer.content) {
-		lexer.current = '\x00'
+	if lexer.atEnd() {
 		return
 	}
-	lexer.position += 1
-	lexer.current = lexer.content[lexer.position]
+	if lexer.current == '\n' {
+		lexer.line += 1
+		lexer.column = 1
+		lexer.byteColumn = 1
+	} else {
+		lexer.column += 1
+		lexer.byteColumn += lexer.width
+	}
+	consumed, _ := lexer.reader.Peek(lexer.width)
+	lexer.text.Write(consumed)
+	lexer.reader.Discard(lexer.width)
+
+	lexer.position += lexer.width
+	lexer.runeOffset += 1
+	lexer.current, lexer.width = lexer.characterAt(0)
 }
 
-func (lexer *Lexer) peek() byte {
-	if lexer.position+1 >= len(lexer.content) {
-		return '\x00'
+func (lexer *Lexer) peek() rune {
+	character, _ := lexer.characterAt(lexer.width)
+	return character
+}
+
+func isWhitespace(character rune) bool {
+	return character == ' ' ||
+		character == '\n' ||
+		character == '\r' ||
+		character == '\t'
+}
+
+func (lexer *Lexer) collectWhitespace(trailing bool) *Trivia {
+	start := lexer.location()
+	mark := lexer.mark()
+
+	for !lexer.atEnd() && isWhitespace(lexer.current) {
+		if trailing && (lexer.current == '\n' || lexer.current == '\r') {
+			break
+		}
+		lexer.advance()
+	}
+
+	return &Trivia{
+		Type: TRIVIA_WHITESPACE,
+		Text: lexer.recorded(mark),
+		Span: Span{Start: start, End: lexer.location()},
 	}
-	return lexer.content[lexer.position+1]
 }
 
-func (lexer *Lexer) skipWhitespaces() {
-	for lexer.current == ' ' ||
-		lexer.current == '\n' ||
-		lexer.current == '\r' ||
-		lexer.current == '\t' {
+func (lexer *Lexer) collectLineComment() *Trivia {
+	start := lexer.location()
+	mark := lexer.mark()
+
+	for !lexer.atEnd() && lexer.current != '\n' && lexer.current != '\r' {
 		lexer.advance()
 	}
+
+	return &Trivia{
+		Type: TRIVIA_LINE_COMMENT,
+		Text: lexer.recorded(mark),
+		Span: Span{Start: start, End: lexer.location()},
+	}
+}
+
+func (lexer *Lexer) collectBlockComment() (*Trivia, bool) {
+	start := lexer.location()
+	mark := lexer.mark()
+
+	lexer.advance()
+	lexer.advance()
+	for !lexer.atEnd() && !(lexer.current == '*' && lexer.peek() == '/') {
+		lexer.advance()
+	}
+	terminated := !lexer.atEnd()
+	lexer.advance()
+	lexer.advance()
+
+	return &Trivia{
+		Type: TRIVIA_BLOCK_COMMENT,
+		Text: lexer.recorded(mark),
+		Span: Span{Start: start, End: lexer.location()},
+	}, terminated
+}
+
+// Block comments are always leading trivia, an unterminated one is
+// returned as an illegal token instead.
+func (lexer *Lexer) collectTrivia(trailing bool) ([]*Trivia, *Token) {
+	trivia := []*Trivia{}
+
+	for !lexer.atEnd() {
+		switch {
+		case isWhitespace(lexer.current):
+			if trailing && (lexer.current == '\n' || lexer.current == '\r') {
+				return trivia, nil
+			}
+			trivia = append(trivia, lexer.collectWhitespace(trailing))
+		case lexer.current == '/' && lexer.peek() == '/':
+			trivia = append(trivia, lexer.collectLineComment())
+		case lexer.current == '/' && lexer.peek() == '*' && !trailing:
+			comment, terminated := lexer.collectBlockComment()
+			if !terminated {
+				token := NewToken(TOKEN_ILLEGAL, comment.Text)
+				token.Message = "Unterminated block comment."
+				token.Span = comment.Span
+				return trivia, token
+			}
+			trivia = append(trivia, comment)
+		default:
+			return trivia, nil
+		}
+	}
+
+	return trivia, nil
 }
 
 func (lexer *Lexer) collectCurrent(tokenType TokenType) *Token {
@@ -61,6 +231,18 @@ func (lexer *Lexer) collectCurrent(tokenType TokenType) *Token {
 	return token
 }
 
+func (lexer *Lexer) collectIllegal(start int, message string) *Token {
+	token := NewToken(TOKEN_ILLEGAL, lexer.recorded(start))
+	token.Message = message
+	return token
+}
+
+func (lexer *Lexer) collectUnexpected() *Token {
+	token := lexer.collectCurrent(TOKEN_ILLEGAL)
+	token.Message = fmt.Sprintf("Unexpected character %q.", token.Literal)
+	return token
+}
+
 func (lexer *Lexer) collectWithNext(tokenType TokenType) *Token {
 	token := NewToken(tokenType, string(lexer.current)+string(lexer.peek()))
 	lexer.advance()
@@ -68,25 +250,288 @@ func (lexer *Lexer) collectWithNext(tokenType TokenType) *Token {
 	return token
 }
 
+func isDigitOfBase(character rune, base int) bool {
+	switch base {
+	case 2:
+		return character == '0' || character == '1'
+	case 8:
+		return character >= '0' && character <= '7'
+	case 16:
+		return isHexadecimal(character)
+	default:
+		return isDigit(character)
+	}
+}
+
+func baseName(base int) string {
+	switch base {
+	case 2:
+		return "binary"
+	case 8:
+		return "octal"
+	case 16:
+		return "hexadecimal"
+	default:
+		return "decimal"
+	}
+}
+
+// collectDigits advances over digits of the base and the single underscores
+// separating them, it returns the number of digits and a message for a
+// misplaced underscore.
+func (lexer *Lexer) collectDigits(base int) (int, string) {
+	message := ""
+	digits := 0
+	for isDigitOfBase(lexer.current, base) || lexer.current == '_' {
+		if lexer.current == '_' {
+			if digits == 0 || !isDigitOfBase(lexer.peek(), base) {
+				message = "Underscores in integer literals must separate digits."
+			}
+		} else {
+			digits += 1
+		}
+		lexer.advance()
+	}
+	return digits, message
+}
+
+// Integer literals may start with a 0x, 0o or 0b prefix and separate digits
+// with single underscores, the literal keeps the text as written. Decimal
+// literals may also have a fraction and an exponent as in 1.5e3, the parser
+// checks that their value is an integer.
 func (lexer *Lexer) collectIntegerLiteral() *Token {
-	var buffer bytes.Buffer
+	start := lexer.mark()
+	base := 10
 
-	for isDigit(lexer.current) {
-		buffer.WriteByte(lexer.current)
+	if lexer.current == '0' {
+		switch lexer.peek() {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 10 {
+			lexer.advance()
+			lexer.advance()
+		}
+	}
+
+	digits, message := lexer.collectDigits(base)
+
+	if base == 10 && digits > 0 {
+		if lexer.current == '.' && isDigit(lexer.peek()) {
+			lexer.advance()
+			if _, fractionMessage := lexer.collectDigits(base); fractionMessage != "" {
+				message = fractionMessage
+			}
+		}
+		if lexer.current == 'e' || lexer.current == 'E' {
+			lexer.advance()
+			if lexer.current == '+' || lexer.current == '-' {
+				lexer.advance()
+			}
+			exponentDigits, exponentMessage := lexer.collectDigits(base)
+			if exponentMessage != "" {
+				message = exponentMessage
+			} else if exponentDigits == 0 {
+				message = "The exponent of the decimal literal has no digits."
+			}
+		}
+	}
+
+	if isAlphanumeric(lexer.current) {
+		if isDigit(lexer.current) {
+			message = fmt.Sprintf(
+				"Invalid digit %q in %s literal.",
+				lexer.current,
+				baseName(base),
+			)
+		} else {
+			message = fmt.Sprintf(
+				"Invalid character %q in integer literal.",
+				lexer.current,
+			)
+		}
+		for isAlphanumeric(lexer.current) {
+			lexer.advance()
+		}
+	} else if digits == 0 && message == "" {
+		message = fmt.Sprintf("The %s literal has no digits.", baseName(base))
+	}
+
+	if message != "" {
+		return lexer.collectIllegal(start, message)
+	}
+
+	return NewToken(TOKEN_INTEGER, lexer.recorded(start))
+}
+
+func isHexadecimal(character rune) bool {
+	return isDigit(character) ||
+		(character >= 'a' && character <= 'f') ||
+		(character >= 'A' && character <= 'F')
+}
+
+func (lexer *Lexer) collectUnicodeEscape(buffer *bytes.Buffer) string {
+	if lexer.current != '{' {
+		return "Invalid unicode escape, expected \"{\" after \"\\u\"."
+	}
+	lexer.advance()
+
+	var digits bytes.Buffer
+	for isHexadecimal(lexer.current) {
+		digits.WriteRune(lexer.current)
 		lexer.advance()
 	}
 
-	return NewToken(TOKEN_INTEGER, buffer.String())
+	if lexer.current != '}' {
+		return "Invalid unicode escape, expected \"}\" after the hexadecimal digits."
+	}
+	lexer.advance()
+
+	if digits.Len() < 1 || digits.Len() > 6 {
+		return fmt.Sprintf(
+			"Invalid unicode escape \"\\u{%s}\", expected 1 to 6 hexadecimal digits.",
+			digits.String(),
+		)
+	}
+
+	codePoint, _ := strconv.ParseUint(digits.String(), 16, 32)
+	character := rune(codePoint)
+	if !utf8.ValidRune(character) {
+		return fmt.Sprintf(
+			"Invalid unicode escape \"\\u{%s}\", not a valid code point.",
+			digits.String(),
+		)
+	}
+
+	buffer.WriteRune(character)
+	return ""
 }
 
+func (lexer *Lexer) collectEscape(buffer *bytes.Buffer) string {
+	lexer.advance()
+
+	escaped := lexer.current
+	lexer.advance()
+
+	switch escaped {
+	case 'n':
+		buffer.WriteByte('\n')
+	case 't':
+		buffer.WriteByte('\t')
+	case 'r':
+		buffer.WriteByte('\r')
+	case '0':
+		buffer.WriteByte('\x00')
+	case '\\':
+		buffer.WriteByte('\\')
+	case '"':
+		buffer.WriteByte('"')
+	case '$':
+		buffer.WriteByte('$')
+	case 'u':
+		return lexer.collectUnicodeEscape(buffer)
+	default:
+		return fmt.Sprintf("Invalid escape sequence \"\\%c\".", escaped)
+	}
+
+	return ""
+}
+
+// Collects a string literal, or the part of a template string that starts at
+// the opening quote or at the brace closing an embedded expression, and ends
+// at the closing quote or at the "${" opening the next embedded expression.
 func (lexer *Lexer) collectStringLiteral() *Token {
 	var buffer bytes.Buffer
 
+	start := lexer.mark()
+	message := ""
+	resuming := lexer.current == '}'
+
 	lexer.advance()
-	// TODO: handle escaope characters
-	for lexer.current != '"' {
-		buffer.WriteByte(lexer.current)
+	for !lexer.atEnd() && lexer.current != '"' {
+		if lexer.current == '$' && lexer.peek() == '{' {
+			break
+		}
+		if lexer.current != '\\' {
+			buffer.WriteRune(lexer.current)
+			lexer.advance()
+			continue
+		}
+		escapeMessage := lexer.collectEscape(&buffer)
+		if message == "" {
+			message = escapeMessage
+		}
+	}
+
+	if lexer.atEnd() {
+		if resuming {
+			lexer.templates = lexer.templates[:len(lexer.templates)-1]
+		}
+		return lexer.collectIllegal(start, "Unterminated string literal.")
+	}
+
+	var tokenType TokenType
+	if lexer.current == '$' {
 		lexer.advance()
+		lexer.advance()
+		if resuming {
+			tokenType = TOKEN_TEMPLATE_MIDDLE
+		} else {
+			lexer.templates = append(lexer.templates, 0)
+			tokenType = TOKEN_TEMPLATE_HEAD
+		}
+	} else {
+		lexer.advance()
+		if resuming {
+			lexer.templates = lexer.templates[:len(lexer.templates)-1]
+			tokenType = TOKEN_TEMPLATE_TAIL
+		} else {
+			tokenType = TOKEN_STRING
+		}
+	}
+
+	if message != "" {
+		return lexer.collectIllegal(start, message)
+	}
+
+	return NewToken(tokenType, buffer.String())
+}
+
+func (lexer *Lexer) collectOpenBrace() *Token {
+	if len(lexer.templates) > 0 {
+		lexer.templates[len(lexer.templates)-1] += 1
+	}
+	return lexer.collectCurrent(TOKEN_OPEN_BRACE)
+}
+
+func (lexer *Lexer) collectCloseBrace() *Token {
+	if len(lexer.templates) == 0 {
+		return lexer.collectCurrent(TOKEN_CLOSE_BRACE)
+	}
+	if lexer.templates[len(lexer.templates)-1] == 0 {
+		return lexer.collectStringLiteral()
+	}
+	lexer.templates[len(lexer.templates)-1] -= 1
+	return lexer.collectCurrent(TOKEN_CLOSE_BRACE)
+}
+
+func (lexer *Lexer) collectRawStringLiteral() *Token {
+	var buffer bytes.Buffer
+
+	start := lexer.mark()
+
+	lexer.advance()
+	for !lexer.atEnd() && lexer.current != '`' {
+		buffer.WriteRune(lexer.current)
+		lexer.advance()
+	}
+
+	if lexer.atEnd() {
+		return lexer.collectIllegal(start, "Unterminated raw string literal.")
 	}
 	lexer.advance()
 
@@ -97,7 +542,7 @@ func (lexer *Lexer) collectIdentifierOrKeyword() *Token {
 	var buffer bytes.Buffer
 
 	for isAlphanumeric(lexer.current) {
-		buffer.WriteByte(lexer.current)
+		buffer.WriteRune(lexer.current)
 		lexer.advance()
 	}
 
@@ -108,6 +553,8 @@ func (lexer *Lexer) collectIdentifierOrKeyword() *Token {
 	switch text {
 	case "let":
 		tokenType = TOKEN_LET
+	case "const":
+		tokenType = TOKEN_CONST
 	case "fn":
 		tokenType = TOKEN_FUNCTION
 	case "return":
@@ -120,6 +567,18 @@ func (lexer *Lexer) collectIdentifierOrKeyword() *Token {
 		tokenType = TOKEN_TRUE
 	case "false":
 		tokenType = TOKEN_FALSE
+	case "null":
+		tokenType = TOKEN_NULL
+	case "while":
+		tokenType = TOKEN_WHILE
+	case "for":
+		tokenType = TOKEN_FOR
+	case "in":
+		tokenType = TOKEN_IN
+	case "break":
+		tokenType = TOKEN_BREAK
+	case "continue":
+		tokenType = TOKEN_CONTINUE
 	default:
 		tokenType = TOKEN_IDENTIFIER
 	}
@@ -127,12 +586,12 @@ func (lexer *Lexer) collectIdentifierOrKeyword() *Token {
 	return NewToken(tokenType, text)
 }
 
-func (lexer *Lexer) Next() *Token {
-	lexer.skipWhitespaces()
+func (lexer *Lexer) collectToken() *Token {
+	if lexer.atEnd() {
+		return NewToken(TOKEN_EOF, "\x00")
+	}
 
 	switch lexer.current {
-	case '\x00':
-		return lexer.collectCurrent(TOKEN_EOF)
 	case '=':
 		if lexer.peek() == '=' {
 			return lexer.collectWithNext(TOKEN_EQUALS)
@@ -143,7 +602,26 @@ func (lexer *Lexer) Next() *Token {
 	case '-':
 		return lexer.collectCurrent(TOKEN_MINUS)
 	case '*':
+		if lexer.peek() == '*' {
+			return lexer.collectWithNext(TOKEN_POWER)
+		}
 		return lexer.collectCurrent(TOKEN_ASTERISK)
+	case '%':
+		return lexer.collectCurrent(TOKEN_PERCENT)
+	case '.':
+		third, _ := lexer.characterAt(lexer.width + 1)
+		if lexer.peek() == '.' && third == '.' {
+			token := NewToken(TOKEN_ELLIPSIS, "...")
+			lexer.advance()
+			lexer.advance()
+			lexer.advance()
+			return token
+		}
+		return lexer.collectUnexpected()
+	case '^':
+		return lexer.collectCurrent(TOKEN_CARET)
+	case '~':
+		return lexer.collectCurrent(TOKEN_TILDE)
 	case '/':
 		return lexer.collectCurrent(TOKEN_SLASH)
 	case '!':
@@ -155,20 +633,36 @@ func (lexer *Lexer) Next() *Token {
 		if lexer.peek() == '=' {
 			return lexer.collectWithNext(TOKEN_GREATER_OR_EQUALS)
 		}
+		if lexer.peek() == '>' {
+			return lexer.collectWithNext(TOKEN_SHIFT_RIGHT)
+		}
 		return lexer.collectCurrent(TOKEN_GREATER)
 	case '<':
 		if lexer.peek() == '=' {
 			return lexer.collectWithNext(TOKEN_LESS_OR_EQUALS)
 		}
+		if lexer.peek() == '<' {
+			return lexer.collectWithNext(TOKEN_SHIFT_LEFT)
+		}
 		return lexer.collectCurrent(TOKEN_LESS)
+	case '&':
+		if lexer.peek() == '&' {
+			return lexer.collectWithNext(TOKEN_AND)
+		}
+		return lexer.collectCurrent(TOKEN_AMPERSAND)
+	case '|':
+		if lexer.peek() == '|' {
+			return lexer.collectWithNext(TOKEN_OR)
+		}
+		return lexer.collectCurrent(TOKEN_PIPE)
 	case '(':
 		return lexer.collectCurrent(TOKEN_OPEN_PAREN)
 	case ')':
 		return lexer.collectCurrent(TOKEN_CLOSE_PAREN)
 	case '{':
-		return lexer.collectCurrent(TOKEN_OPEN_BRACE)
+		return lexer.collectOpenBrace()
 	case '}':
-		return lexer.collectCurrent(TOKEN_CLOSE_BRACE)
+		return lexer.collectCloseBrace()
 	case '[':
 		return lexer.collectCurrent(TOKEN_OPEN_BRACKET)
 	case ']':
@@ -177,10 +671,20 @@ func (lexer *Lexer) Next() *Token {
 		return lexer.collectCurrent(TOKEN_COMMA)
 	case ':':
 		return lexer.collectCurrent(TOKEN_COLON)
+	case '?':
+		switch lexer.peek() {
+		case '.':
+			return lexer.collectWithNext(TOKEN_QUESTION_DOT)
+		case '?':
+			return lexer.collectWithNext(TOKEN_COALESCE)
+		}
+		return lexer.collectCurrent(TOKEN_QUESTION)
 	case ';':
 		return lexer.collectCurrent(TOKEN_SEMICOLON)
 	case '"':
 		return lexer.collectStringLiteral()
+	case '`':
+		return lexer.collectRawStringLiteral()
 	default:
 		if isDigit(lexer.current) {
 			return lexer.collectIntegerLiteral()
@@ -188,6 +692,66 @@ func (lexer *Lexer) Next() *Token {
 		if isAlphabetic(lexer.current) {
 			return lexer.collectIdentifierOrKeyword()
 		}
-		return lexer.collectCurrent(TOKEN_ILLEGAL)
+		if lexer.atInvalidEncoding() {
+			token := lexer.collectCurrent(TOKEN_ILLEGAL)
+			token.Message = "Invalid UTF-8 encoding."
+			return token
+		}
+		return lexer.collectUnexpected()
+	}
+}
+
+func (lexer *Lexer) Next() *Token {
+	lexer.text.Reset()
+
+	leadingTrivia, illegal := lexer.collectTrivia(false)
+	if illegal != nil {
+		illegal.LeadingTrivia = leadingTrivia
+		illegal.TrailingTrivia = []*Trivia{}
+		return illegal
 	}
+
+	start := lexer.location()
+	token := lexer.collectToken()
+	if token.Type == TOKEN_EOF && lexer.readError != nil && !lexer.readFailed {
+		lexer.readFailed = true
+		token = NewToken(TOKEN_ILLEGAL, "")
+		token.Message = fmt.Sprintf("Failed to read source: %s.", lexer.readError)
+	}
+	token.Span = Span{Start: start, End: lexer.location()}
+	token.LeadingTrivia = leadingTrivia
+	token.TrailingTrivia, _ = lexer.collectTrivia(true)
+
+	return token
+}
+
+func Quote(value string) string {
+	var builder strings.Builder
+
+	builder.WriteByte('"')
+	for _, character := range value {
+		switch character {
+		case '\n':
+			builder.WriteString("\\n")
+		case '\t':
+			builder.WriteString("\\t")
+		case '\r':
+			builder.WriteString("\\r")
+		case '\x00':
+			builder.WriteString("\\0")
+		case '\\':
+			builder.WriteString("\\\\")
+		case '"':
+			builder.WriteString("\\\"")
+		default:
+			if unicode.IsPrint(character) {
+				builder.WriteRune(character)
+			} else {
+				fmt.Fprintf(&builder, "\\u{%X}", character)
+			}
+		}
+	}
+	builder.WriteByte('"')
+
+	return strings.ReplaceAll(builder.String(), "${", "\\${")
 }
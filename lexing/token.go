@@ -1,22 +1,34 @@
 package lexing
 
+import "fmt"
+
 const (
 	_ = iota
 	TOKEN_EOF
 	TOKEN_ILLEGAL
 
 	TOKEN_LET
+	TOKEN_CONST
 	TOKEN_FUNCTION
 	TOKEN_RETURN
 	TOKEN_IF
 	TOKEN_ELSE
 	TOKEN_TRUE
 	TOKEN_FALSE
+	TOKEN_NULL
+	TOKEN_WHILE
+	TOKEN_FOR
+	TOKEN_IN
+	TOKEN_BREAK
+	TOKEN_CONTINUE
 
 	TOKEN_IDENTIFIER
 
 	TOKEN_INTEGER
 	TOKEN_STRING
+	TOKEN_TEMPLATE_HEAD
+	TOKEN_TEMPLATE_MIDDLE
+	TOKEN_TEMPLATE_TAIL
 
 	TOKEN_ASSIGN
 	TOKEN_PLUS
@@ -30,6 +42,19 @@ const (
 	TOKEN_GREATER_OR_EQUALS
 	TOKEN_LESS
 	TOKEN_LESS_OR_EQUALS
+	TOKEN_AND
+	TOKEN_OR
+	TOKEN_PERCENT
+	TOKEN_POWER
+	TOKEN_AMPERSAND
+	TOKEN_PIPE
+	TOKEN_CARET
+	TOKEN_TILDE
+	TOKEN_SHIFT_LEFT
+	TOKEN_SHIFT_RIGHT
+	TOKEN_QUESTION_DOT
+	TOKEN_COALESCE
+	TOKEN_ELLIPSIS
 
 	TOKEN_OPEN_PAREN
 	TOKEN_CLOSE_PAREN
@@ -39,14 +64,60 @@ const (
 	TOKEN_CLOSE_BRACKET
 	TOKEN_COMMA
 	TOKEN_COLON
+	TOKEN_QUESTION
 	TOKEN_SEMICOLON
 )
 
 type TokenType int
 
+// Column and RuneOffset count runes, ByteColumn and Offset count bytes. Lines
+// and columns start at one, offsets start at zero.
+type Position struct {
+	File       string
+	Line       int
+	Column     int
+	ByteColumn int
+	Offset     int
+	RuneOffset int
+}
+
+func (position Position) String() string {
+	if position.File == "" {
+		return fmt.Sprintf("%d:%d", position.Line, position.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", position.File, position.Line, position.Column)
+}
+
+type Span struct {
+	Start Position
+	End   Position
+}
+
+const (
+	_ = iota
+	TRIVIA_WHITESPACE
+	TRIVIA_LINE_COMMENT
+	TRIVIA_BLOCK_COMMENT
+)
+
+type TriviaType int
+
+// Trivia is source text that carries no meaning for the grammar. A token owns
+// the trivia before it as leading trivia, and the whitespace and line comment
+// that follow it on the same line as trailing trivia.
+type Trivia struct {
+	Type TriviaType
+	Text string
+	Span Span
+}
+
 type Token struct {
-	Type    TokenType
-	Literal string
+	Type           TokenType
+	Literal        string
+	Span           Span
+	Message        string
+	LeadingTrivia  []*Trivia
+	TrailingTrivia []*Trivia
 }
 
 func TokenTypeToString(tokenType TokenType) string {
@@ -54,15 +125,25 @@ func TokenTypeToString(tokenType TokenType) string {
 		TOKEN_EOF:               "eof",
 		TOKEN_ILLEGAL:           "illegal",
 		TOKEN_LET:               "let",
+		TOKEN_CONST:             "const",
 		TOKEN_FUNCTION:          "function",
 		TOKEN_RETURN:            "return",
 		TOKEN_IF:                "if",
 		TOKEN_ELSE:              "else",
 		TOKEN_TRUE:              "true",
 		TOKEN_FALSE:             "false",
+		TOKEN_NULL:              "null",
+		TOKEN_WHILE:             "while",
+		TOKEN_FOR:               "for",
+		TOKEN_IN:                "in",
+		TOKEN_BREAK:             "break",
+		TOKEN_CONTINUE:          "continue",
 		TOKEN_IDENTIFIER:        "identifier",
 		TOKEN_INTEGER:           "integer",
 		TOKEN_STRING:            "string",
+		TOKEN_TEMPLATE_HEAD:     "template head",
+		TOKEN_TEMPLATE_MIDDLE:   "template middle",
+		TOKEN_TEMPLATE_TAIL:     "template tail",
 		TOKEN_ASSIGN:            "assign",
 		TOKEN_PLUS:              "plus",
 		TOKEN_MINUS:             "minus",
@@ -75,6 +156,19 @@ func TokenTypeToString(tokenType TokenType) string {
 		TOKEN_GREATER_OR_EQUALS: "greater or equals",
 		TOKEN_LESS:              "less",
 		TOKEN_LESS_OR_EQUALS:    "less or equals",
+		TOKEN_AND:               "and",
+		TOKEN_OR:                "or",
+		TOKEN_PERCENT:           "percent",
+		TOKEN_POWER:             "power",
+		TOKEN_AMPERSAND:         "ampersand",
+		TOKEN_PIPE:              "pipe",
+		TOKEN_CARET:             "caret",
+		TOKEN_TILDE:             "tilde",
+		TOKEN_SHIFT_LEFT:        "shift left",
+		TOKEN_SHIFT_RIGHT:       "shift right",
+		TOKEN_QUESTION_DOT:      "question dot",
+		TOKEN_COALESCE:          "coalesce",
+		TOKEN_ELLIPSIS:          "ellipsis",
 		TOKEN_OPEN_PAREN:        "open paren",
 		TOKEN_CLOSE_PAREN:       "close paren",
 		TOKEN_OPEN_BRACE:        "open brace",
@@ -83,6 +177,7 @@ func TokenTypeToString(tokenType TokenType) string {
 		TOKEN_CLOSE_BRACKET:     "close bracket",
 		TOKEN_COMMA:             "comma",
 		TOKEN_COLON:             "colon",
+		TOKEN_QUESTION:          "question",
 		TOKEN_SEMICOLON:         "semicolon",
 	}
 	return tokenTypeToString[tokenType]
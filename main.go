@@ -2,16 +2,37 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
+	"monkey/analyzing"
 	"monkey/evaluating"
 	"monkey/lexing"
 	"monkey/parsing"
 	"os"
+	"slices"
 	"strings"
 )
 
 const PROMPT = ">> "
 
+var warnShadowing = flag.Bool("warn-shadowing", false, "warn when a declaration shadows an outer one")
+
+// reportDiagnostics prints errors to stdout and warnings to stderr, and
+// returns false if there was any error.
+func reportDiagnostics(diagnostics []*parsing.Diagnostic) bool {
+	ok := true
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == parsing.SEVERITY_ERROR {
+			fmt.Println(diagnostic.String())
+			ok = false
+		} else {
+			fmt.Fprintln(os.Stderr, "warning: "+diagnostic.String())
+		}
+	}
+	return ok
+}
+
 func repl() {
 	fmt.Println("Monkey language REPL (Read Eval Print Loop).")
 
@@ -36,6 +57,10 @@ func repl() {
 			return
 		}
 
+		if current == "" {
+			continue
+		}
+
 		if current[len(current)-1] != ';' {
 			current += ";"
 		}
@@ -51,28 +76,46 @@ func repl() {
 			continue
 		}
 
+		analyzer := analyzing.NewAnalyzer(analyzing.Options{
+			WarnShadowing: *warnShadowing,
+		})
+		// earlier lines were reported when they were entered
+		diagnostics := slices.DeleteFunc(analyzer.Analyze(ast), func(diagnostic *parsing.Diagnostic) bool {
+			return diagnostic.Span.Start.Offset < len(content)
+		})
+		if !reportDiagnostics(diagnostics) {
+			continue
+		}
+
 		env := evaluating.NewEnvironment(nil)
 		evaluating.InjectBuiltinFunctions(env)
 		object := evaluating.Eval(env, ast)
 
-		if object.Type() != evaluating.OBJECT_ERROR {
-			content += current
+		if error, ok := object.(*evaluating.ObjectError); ok {
+			fmt.Println(error.StackTrace())
+			continue
 		}
 
+		content += current
 		fmt.Println(object.Inspect())
 	}
 
 }
 
 func file() {
-	filepath := os.Args[1]
+	filepath := flag.Arg(0)
 
-	content, err := os.ReadFile(filepath)
-	if err != nil {
-		panic(err)
+	var source io.Reader = os.Stdin
+	if filepath != "-" {
+		file, err := os.Open(filepath)
+		if err != nil {
+			panic(err)
+		}
+		defer file.Close()
+		source = file
 	}
 
-	lexer := lexing.NewLexer(string(content))
+	lexer := lexing.NewReaderLexer(filepath, source)
 	parser := parsing.NewParser(lexer)
 	ast := parser.Parse()
 
@@ -83,17 +126,26 @@ func file() {
 		return
 	}
 
+	analyzer := analyzing.NewAnalyzer(analyzing.Options{
+		WarnShadowing: *warnShadowing,
+	})
+	if !reportDiagnostics(analyzer.Analyze(ast)) {
+		return
+	}
+
 	env := evaluating.NewEnvironment(nil)
 	evaluating.InjectBuiltinFunctions(env)
 	object := evaluating.Eval(env, ast)
 
-	if object.Type() == evaluating.OBJECT_ERROR {
-		fmt.Println(object.Inspect())
+	if error, ok := object.(*evaluating.ObjectError); ok {
+		fmt.Println(error.StackTrace())
 	}
 }
 
 func main() {
-	if len(os.Args) == 1 {
+	flag.Parse()
+
+	if flag.NArg() == 0 {
 		repl()
 	} else {
 		file()
@@ -3,6 +3,7 @@ package parsing
 import (
 	"fmt"
 	"monkey/lexing"
+	"strings"
 )
 
 const (
@@ -24,6 +25,20 @@ const (
 	AST_INDEX
 	AST_IF_ELSE
 	AST_ASSIGNMENT
+	AST_TEMPLATE_STRING
+	AST_LOGICAL_EXPRESSION
+	AST_WHILE_STATEMENT
+	AST_FOR_STATEMENT
+	AST_BREAK_STATEMENT
+	AST_CONTINUE_STATEMENT
+	AST_CONDITIONAL
+	AST_NULL_LITERAL
+	AST_BAD_EXPRESSION
+	AST_ARRAY_PATTERN
+	AST_HASH_PATTERN
+	AST_PARAMETER
+	AST_SPREAD
+	AST_SLICE
 )
 
 type AstType int
@@ -31,6 +46,7 @@ type AstType int
 type AstNode interface {
 	Type() AstType
 	TokenLiteral() string
+	GetSpan() lexing.Span
 	String() string
 }
 
@@ -44,8 +60,14 @@ type AstExpression interface {
 	expression()
 }
 
+type AstPattern interface {
+	AstNode
+	pattern()
+}
+
 type AstCompound struct {
 	Token      *lexing.Token
+	Span       lexing.Span
 	Statements []AstStatement
 }
 
@@ -55,6 +77,9 @@ func (compound *AstCompound) Type() AstType {
 func (compound *AstCompound) TokenLiteral() string {
 	return compound.Token.Literal
 }
+func (compound *AstCompound) GetSpan() lexing.Span {
+	return compound.Span
+}
 func (compound *AstCompound) String() string {
 	text := ""
 	for index, statement := range compound.Statements {
@@ -68,6 +93,7 @@ func (compound *AstCompound) String() string {
 
 type AstExpressionStatement struct {
 	Token      *lexing.Token
+	Span       lexing.Span
 	Expression AstExpression
 }
 
@@ -78,14 +104,23 @@ func (expressionStatement *AstExpressionStatement) Type() AstType {
 func (expressionStatement *AstExpressionStatement) TokenLiteral() string {
 	return expressionStatement.Token.Literal
 }
+func (expressionStatement *AstExpressionStatement) GetSpan() lexing.Span {
+	return expressionStatement.Span
+}
 func (expressionStatement *AstExpressionStatement) String() string {
 	return expressionStatement.Expression.String() + ";"
 }
 
+// A destructuring let binds a pattern instead of a single identifier, only
+// one of Identifier and Pattern is set. Constant is set for const statements,
+// which share the let syntax but always have a value.
 type AstLetStatement struct {
 	Token      *lexing.Token
+	Span       lexing.Span
 	Identifier *AstIdentifier
+	Pattern    AstPattern
 	Value      AstExpression
+	Constant   bool
 }
 
 func (letStatement *AstLetStatement) statement() {}
@@ -95,10 +130,16 @@ func (letStatement *AstLetStatement) Type() AstType {
 func (letStatement *AstLetStatement) TokenLiteral() string {
 	return letStatement.Token.Literal
 }
+func (letStatement *AstLetStatement) GetSpan() lexing.Span {
+	return letStatement.Span
+}
 func (letStatement *AstLetStatement) String() string {
-	text := letStatement.TokenLiteral() +
-		" " +
-		letStatement.Identifier.String()
+	text := letStatement.TokenLiteral() + " "
+	if letStatement.Pattern != nil {
+		text += letStatement.Pattern.String()
+	} else {
+		text += letStatement.Identifier.String()
+	}
 	if letStatement.Value != nil {
 		text += " = " + letStatement.Value.String()
 	}
@@ -109,6 +150,7 @@ func (letStatement *AstLetStatement) String() string {
 
 type AstReturnStatement struct {
 	Token *lexing.Token
+	Span  lexing.Span
 	Value AstExpression
 }
 
@@ -119,6 +161,9 @@ func (returnStatement *AstReturnStatement) Type() AstType {
 func (returnStatement *AstReturnStatement) TokenLiteral() string {
 	return returnStatement.Token.Literal
 }
+func (returnStatement *AstReturnStatement) GetSpan() lexing.Span {
+	return returnStatement.Span
+}
 func (returnStatement *AstReturnStatement) String() string {
 	text := returnStatement.TokenLiteral()
 	if returnStatement.Value != nil {
@@ -131,6 +176,7 @@ func (returnStatement *AstReturnStatement) String() string {
 
 type AstIntegerLiteral struct {
 	Token *lexing.Token
+	Span  lexing.Span
 	Value int64
 }
 
@@ -141,12 +187,16 @@ func (integerLiteral *AstIntegerLiteral) Type() AstType {
 func (integerLiteral *AstIntegerLiteral) TokenLiteral() string {
 	return integerLiteral.Token.Literal
 }
+func (integerLiteral *AstIntegerLiteral) GetSpan() lexing.Span {
+	return integerLiteral.Span
+}
 func (integerLiteral *AstIntegerLiteral) String() string {
 	return fmt.Sprintf("%d", integerLiteral.Value)
 }
 
 type AstBooleanLiteral struct {
 	Token *lexing.Token
+	Span  lexing.Span
 	Value bool
 }
 
@@ -157,12 +207,35 @@ func (booleanLiteral *AstBooleanLiteral) Type() AstType {
 func (booleanLiteral *AstBooleanLiteral) TokenLiteral() string {
 	return booleanLiteral.Token.Literal
 }
+func (booleanLiteral *AstBooleanLiteral) GetSpan() lexing.Span {
+	return booleanLiteral.Span
+}
 func (booleanLiteral *AstBooleanLiteral) String() string {
 	return fmt.Sprintf("%t", booleanLiteral.Value)
 }
 
+type AstNullLiteral struct {
+	Token *lexing.Token
+	Span  lexing.Span
+}
+
+func (nullLiteral *AstNullLiteral) expression() {}
+func (nullLiteral *AstNullLiteral) Type() AstType {
+	return AST_NULL_LITERAL
+}
+func (nullLiteral *AstNullLiteral) TokenLiteral() string {
+	return nullLiteral.Token.Literal
+}
+func (nullLiteral *AstNullLiteral) GetSpan() lexing.Span {
+	return nullLiteral.Span
+}
+func (nullLiteral *AstNullLiteral) String() string {
+	return "null"
+}
+
 type AstPrefixExpression struct {
 	Token    *lexing.Token
+	Span     lexing.Span
 	Operator string
 	Right    AstExpression
 }
@@ -174,6 +247,9 @@ func (prefixExpression *AstPrefixExpression) Type() AstType {
 func (prefixExpression *AstPrefixExpression) TokenLiteral() string {
 	return prefixExpression.Token.Literal
 }
+func (prefixExpression *AstPrefixExpression) GetSpan() lexing.Span {
+	return prefixExpression.Span
+}
 func (prefixExpression *AstPrefixExpression) String() string {
 	return "(" +
 		prefixExpression.Operator +
@@ -183,6 +259,7 @@ func (prefixExpression *AstPrefixExpression) String() string {
 
 type AstInfixExpression struct {
 	Token    *lexing.Token
+	Span     lexing.Span
 	Left     AstExpression
 	Operator string
 	Right    AstExpression
@@ -195,6 +272,9 @@ func (infixExpression *AstInfixExpression) Type() AstType {
 func (infixExpression *AstInfixExpression) TokenLiteral() string {
 	return infixExpression.Token.Literal
 }
+func (infixExpression *AstInfixExpression) GetSpan() lexing.Span {
+	return infixExpression.Span
+}
 func (infixExpression *AstInfixExpression) String() string {
 	return "(" +
 		infixExpression.Left.String() +
@@ -207,6 +287,7 @@ func (infixExpression *AstInfixExpression) String() string {
 
 type AstIdentifier struct {
 	Token *lexing.Token
+	Span  lexing.Span
 	Name  string
 }
 
@@ -217,12 +298,16 @@ func (identifier *AstIdentifier) Type() AstType {
 func (identifier *AstIdentifier) TokenLiteral() string {
 	return identifier.Token.Literal
 }
+func (identifier *AstIdentifier) GetSpan() lexing.Span {
+	return identifier.Span
+}
 func (identifier *AstIdentifier) String() string {
 	return identifier.Name
 }
 
 type AstFunctionCall struct {
 	Token     *lexing.Token
+	Span      lexing.Span
 	Left      AstExpression
 	Arguments []AstExpression
 }
@@ -234,6 +319,9 @@ func (functionCall *AstFunctionCall) Type() AstType {
 func (functionCall *AstFunctionCall) TokenLiteral() string {
 	return functionCall.Token.Literal
 }
+func (functionCall *AstFunctionCall) GetSpan() lexing.Span {
+	return functionCall.Span
+}
 func (functionCall *AstFunctionCall) String() string {
 	text := functionCall.Left.String() + "("
 
@@ -248,10 +336,15 @@ func (functionCall *AstFunctionCall) String() string {
 	return text
 }
 
+// Optional indexes are written a?.[k] or a?.field and evaluate to null
+// instead of indexing when the left side is null. The field form stores the
+// field name as a string literal holding the identifier token.
 type AstIndex struct {
-	Token *lexing.Token
-	Left  AstExpression
-	Index AstExpression
+	Token    *lexing.Token
+	Span     lexing.Span
+	Left     AstExpression
+	Index    AstExpression
+	Optional bool
 }
 
 func (index *AstIndex) expression() {}
@@ -261,12 +354,23 @@ func (index *AstIndex) Type() AstType {
 func (index *AstIndex) TokenLiteral() string {
 	return index.Token.Literal
 }
+func (index *AstIndex) GetSpan() lexing.Span {
+	return index.Span
+}
 func (index *AstIndex) String() string {
-	return index.Left.String() + "[" + index.Index.String() + "]"
+	if !index.Optional {
+		return index.Left.String() + "[" + index.Index.String() + "]"
+	}
+	field, ok := index.Index.(*AstStringLiteral)
+	if ok && field.Token.Type == lexing.TOKEN_IDENTIFIER {
+		return index.Left.String() + "?." + field.Value
+	}
+	return index.Left.String() + "?.[" + index.Index.String() + "]"
 }
 
 type AstStringLiteral struct {
 	Token *lexing.Token
+	Span  lexing.Span
 	Value string
 }
 
@@ -277,12 +381,16 @@ func (stringLiteral *AstStringLiteral) Type() AstType {
 func (stringLiteral *AstStringLiteral) TokenLiteral() string {
 	return stringLiteral.Token.Literal
 }
+func (stringLiteral *AstStringLiteral) GetSpan() lexing.Span {
+	return stringLiteral.Span
+}
 func (stringLiteral *AstStringLiteral) String() string {
-	return "\"" + stringLiteral.Value + "\""
+	return lexing.Quote(stringLiteral.Value)
 }
 
 type AstArrayLiteral struct {
 	Token *lexing.Token
+	Span  lexing.Span
 	Items []AstExpression
 }
 
@@ -293,6 +401,9 @@ func (arrayLiteral *AstArrayLiteral) Type() AstType {
 func (arrayLiteral *AstArrayLiteral) TokenLiteral() string {
 	return arrayLiteral.Token.Literal
 }
+func (arrayLiteral *AstArrayLiteral) GetSpan() lexing.Span {
+	return arrayLiteral.Span
+}
 func (arrayLiteral *AstArrayLiteral) String() string {
 	text := "["
 	for index, item := range arrayLiteral.Items {
@@ -306,6 +417,8 @@ func (arrayLiteral *AstArrayLiteral) String() string {
 	return text
 }
 
+// A spread entry is stored as a pair with the spread as its key and no value,
+// so that it keeps its place among the other pairs.
 type AstHashLiteralPair struct {
 	Key   AstExpression
 	Value AstExpression
@@ -313,6 +426,7 @@ type AstHashLiteralPair struct {
 
 type AstHashLiteral struct {
 	Token *lexing.Token
+	Span  lexing.Span
 	Pairs []*AstHashLiteralPair
 }
 
@@ -323,10 +437,17 @@ func (hashLiteral *AstHashLiteral) Type() AstType {
 func (hashLiteral *AstHashLiteral) TokenLiteral() string {
 	return hashLiteral.Token.Literal
 }
+func (hashLiteral *AstHashLiteral) GetSpan() lexing.Span {
+	return hashLiteral.Span
+}
 func (hashLiteral *AstHashLiteral) String() string {
 	text := "{"
 	for index, pair := range hashLiteral.Pairs {
-		text += pair.Key.String() + ": " + pair.Value.String()
+		if pair.Value == nil {
+			text += pair.Key.String()
+		} else {
+			text += pair.Key.String() + ": " + pair.Value.String()
+		}
 		if index < len(hashLiteral.Pairs)-1 {
 			text += ", "
 		}
@@ -339,7 +460,8 @@ func (hashLiteral *AstHashLiteral) String() string {
 
 type AstFunctionDefinition struct {
 	Token      *lexing.Token
-	Parameters []*AstIdentifier
+	Span       lexing.Span
+	Parameters []*AstParameter
 	Body       *AstCompound
 }
 
@@ -350,6 +472,9 @@ func (functionDefinition *AstFunctionDefinition) Type() AstType {
 func (functionDefinition *AstFunctionDefinition) TokenLiteral() string {
 	return functionDefinition.Token.Literal
 }
+func (functionDefinition *AstFunctionDefinition) GetSpan() lexing.Span {
+	return functionDefinition.Span
+}
 func (functionDefinition *AstFunctionDefinition) String() string {
 	text := functionDefinition.TokenLiteral() + " ("
 
@@ -365,11 +490,15 @@ func (functionDefinition *AstFunctionDefinition) String() string {
 	return text
 }
 
+// At most one of Else and ElseIf is set, an else-if chain nests the next
+// if-else expression in ElseIf.
 type AstIfElse struct {
 	Token     *lexing.Token
+	Span      lexing.Span
 	Condition AstExpression
 	Then      *AstCompound
 	Else      *AstCompound
+	ElseIf    *AstIfElse
 }
 
 func (ifElse *AstIfElse) expression() {}
@@ -379,6 +508,9 @@ func (ifElse *AstIfElse) Type() AstType {
 func (ifElse *AstIfElse) TokenLiteral() string {
 	return ifElse.Token.Literal
 }
+func (ifElse *AstIfElse) GetSpan() lexing.Span {
+	return ifElse.Span
+}
 func (ifElse *AstIfElse) String() string {
 	text := ifElse.TokenLiteral() +
 		" (" +
@@ -387,7 +519,9 @@ func (ifElse *AstIfElse) String() string {
 		ifElse.Then.String() +
 		" }"
 
-	if ifElse.Else != nil {
+	if ifElse.ElseIf != nil {
+		text += " else " + ifElse.ElseIf.String()
+	} else if ifElse.Else != nil {
 		text += " else { " + ifElse.Else.String() + " }"
 	}
 	return text
@@ -395,6 +529,7 @@ func (ifElse *AstIfElse) String() string {
 
 type AstAssignment struct {
 	Token *lexing.Token
+	Span  lexing.Span
 	Left  AstExpression
 	Value AstExpression
 }
@@ -406,6 +541,369 @@ func (assignment *AstAssignment) Type() AstType {
 func (assignment *AstAssignment) TokenLiteral() string {
 	return assignment.Token.Literal
 }
+func (assignment *AstAssignment) GetSpan() lexing.Span {
+	return assignment.Span
+}
 func (assignment *AstAssignment) String() string {
 	return assignment.Left.String() + " = " + assignment.Value.String()
 }
+
+// Parts alternate between string literals and embedded expressions, starting
+// and ending with a string literal that may be empty.
+type AstTemplateString struct {
+	Token *lexing.Token
+	Span  lexing.Span
+	Parts []AstExpression
+}
+
+func (templateString *AstTemplateString) expression() {}
+func (templateString *AstTemplateString) Type() AstType {
+	return AST_TEMPLATE_STRING
+}
+func (templateString *AstTemplateString) TokenLiteral() string {
+	return templateString.Token.Literal
+}
+func (templateString *AstTemplateString) GetSpan() lexing.Span {
+	return templateString.Span
+}
+func (templateString *AstTemplateString) String() string {
+	text := "\""
+	for index, part := range templateString.Parts {
+		if index%2 == 0 {
+			quoted := lexing.Quote(part.(*AstStringLiteral).Value)
+			text += quoted[1 : len(quoted)-1]
+		} else {
+			text += "${" + part.String() + "}"
+		}
+	}
+	text += "\""
+
+	return text
+}
+
+// Unlike infix expressions, the right side of a logical expression is only
+// evaluated when the left side does not decide the result.
+type AstLogicalExpression struct {
+	Token    *lexing.Token
+	Span     lexing.Span
+	Left     AstExpression
+	Operator string
+	Right    AstExpression
+}
+
+func (logicalExpression *AstLogicalExpression) expression() {}
+func (logicalExpression *AstLogicalExpression) Type() AstType {
+	return AST_LOGICAL_EXPRESSION
+}
+func (logicalExpression *AstLogicalExpression) TokenLiteral() string {
+	return logicalExpression.Token.Literal
+}
+func (logicalExpression *AstLogicalExpression) GetSpan() lexing.Span {
+	return logicalExpression.Span
+}
+func (logicalExpression *AstLogicalExpression) String() string {
+	return "(" +
+		logicalExpression.Left.String() +
+		" " +
+		logicalExpression.Operator +
+		" " +
+		logicalExpression.Right.String() +
+		")"
+}
+
+type AstWhileStatement struct {
+	Token     *lexing.Token
+	Span      lexing.Span
+	Condition AstExpression
+	Body      *AstCompound
+}
+
+func (whileStatement *AstWhileStatement) statement() {}
+func (whileStatement *AstWhileStatement) Type() AstType {
+	return AST_WHILE_STATEMENT
+}
+func (whileStatement *AstWhileStatement) TokenLiteral() string {
+	return whileStatement.Token.Literal
+}
+func (whileStatement *AstWhileStatement) GetSpan() lexing.Span {
+	return whileStatement.Span
+}
+func (whileStatement *AstWhileStatement) String() string {
+	return whileStatement.TokenLiteral() +
+		" (" +
+		whileStatement.Condition.String() +
+		") { " +
+		whileStatement.Body.String() +
+		" }"
+}
+
+type AstForStatement struct {
+	Token      *lexing.Token
+	Span       lexing.Span
+	Identifier *AstIdentifier
+	Iterable   AstExpression
+	Body       *AstCompound
+}
+
+func (forStatement *AstForStatement) statement() {}
+func (forStatement *AstForStatement) Type() AstType {
+	return AST_FOR_STATEMENT
+}
+func (forStatement *AstForStatement) TokenLiteral() string {
+	return forStatement.Token.Literal
+}
+func (forStatement *AstForStatement) GetSpan() lexing.Span {
+	return forStatement.Span
+}
+func (forStatement *AstForStatement) String() string {
+	return forStatement.TokenLiteral() +
+		" (" +
+		forStatement.Identifier.String() +
+		" in " +
+		forStatement.Iterable.String() +
+		") { " +
+		forStatement.Body.String() +
+		" }"
+}
+
+type AstBreakStatement struct {
+	Token *lexing.Token
+	Span  lexing.Span
+}
+
+func (breakStatement *AstBreakStatement) statement() {}
+func (breakStatement *AstBreakStatement) Type() AstType {
+	return AST_BREAK_STATEMENT
+}
+func (breakStatement *AstBreakStatement) TokenLiteral() string {
+	return breakStatement.Token.Literal
+}
+func (breakStatement *AstBreakStatement) GetSpan() lexing.Span {
+	return breakStatement.Span
+}
+func (breakStatement *AstBreakStatement) String() string {
+	return breakStatement.TokenLiteral() + ";"
+}
+
+type AstContinueStatement struct {
+	Token *lexing.Token
+	Span  lexing.Span
+}
+
+func (continueStatement *AstContinueStatement) statement() {}
+func (continueStatement *AstContinueStatement) Type() AstType {
+	return AST_CONTINUE_STATEMENT
+}
+func (continueStatement *AstContinueStatement) TokenLiteral() string {
+	return continueStatement.Token.Literal
+}
+func (continueStatement *AstContinueStatement) GetSpan() lexing.Span {
+	return continueStatement.Span
+}
+func (continueStatement *AstContinueStatement) String() string {
+	return continueStatement.TokenLiteral() + ";"
+}
+
+type AstConditional struct {
+	Token     *lexing.Token
+	Span      lexing.Span
+	Condition AstExpression
+	Then      AstExpression
+	Else      AstExpression
+}
+
+func (conditional *AstConditional) expression() {}
+func (conditional *AstConditional) Type() AstType {
+	return AST_CONDITIONAL
+}
+func (conditional *AstConditional) TokenLiteral() string {
+	return conditional.Token.Literal
+}
+func (conditional *AstConditional) GetSpan() lexing.Span {
+	return conditional.Span
+}
+func (conditional *AstConditional) String() string {
+	return "(" +
+		conditional.Condition.String() +
+		" ? " +
+		conditional.Then.String() +
+		" : " +
+		conditional.Else.String() +
+		")"
+}
+
+// A bad expression marks where the parser expected an expression and found
+// none, a diagnostic has always been reported for it.
+type AstBadExpression struct {
+	Token *lexing.Token
+	Span  lexing.Span
+}
+
+func (badExpression *AstBadExpression) expression() {}
+func (badExpression *AstBadExpression) Type() AstType {
+	return AST_BAD_EXPRESSION
+}
+func (badExpression *AstBadExpression) TokenLiteral() string {
+	return badExpression.Token.Literal
+}
+func (badExpression *AstBadExpression) GetSpan() lexing.Span {
+	return badExpression.Span
+}
+func (badExpression *AstBadExpression) String() string {
+	return "<bad expression>"
+}
+
+// Array patterns bind items by position, the rest identifier collects the
+// items after them into a new array.
+type AstArrayPattern struct {
+	Token    *lexing.Token
+	Span     lexing.Span
+	Elements []*AstIdentifier
+	Rest     *AstIdentifier
+}
+
+func (arrayPattern *AstArrayPattern) pattern() {}
+func (arrayPattern *AstArrayPattern) Type() AstType {
+	return AST_ARRAY_PATTERN
+}
+func (arrayPattern *AstArrayPattern) TokenLiteral() string {
+	return arrayPattern.Token.Literal
+}
+func (arrayPattern *AstArrayPattern) GetSpan() lexing.Span {
+	return arrayPattern.Span
+}
+func (arrayPattern *AstArrayPattern) String() string {
+	elements := []string{}
+	for _, element := range arrayPattern.Elements {
+		elements = append(elements, element.String())
+	}
+	if arrayPattern.Rest != nil {
+		elements = append(elements, "..."+arrayPattern.Rest.String())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// The key of a pair holds the identifier or string token it was written as,
+// a shorthand pair binds the value to an identifier named like its key.
+type AstHashPatternPair struct {
+	Key   *AstStringLiteral
+	Value *AstIdentifier
+}
+
+type AstHashPattern struct {
+	Token *lexing.Token
+	Span  lexing.Span
+	Pairs []*AstHashPatternPair
+}
+
+func (hashPattern *AstHashPattern) pattern() {}
+func (hashPattern *AstHashPattern) Type() AstType {
+	return AST_HASH_PATTERN
+}
+func (hashPattern *AstHashPattern) TokenLiteral() string {
+	return hashPattern.Token.Literal
+}
+func (hashPattern *AstHashPattern) GetSpan() lexing.Span {
+	return hashPattern.Span
+}
+func (hashPattern *AstHashPattern) String() string {
+	pairs := []string{}
+	for _, pair := range hashPattern.Pairs {
+		if pair.Key.Token.Type != lexing.TOKEN_IDENTIFIER {
+			pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+		} else if pair.Key.Value != pair.Value.Name {
+			pairs = append(pairs, pair.Key.Value+": "+pair.Value.String())
+		} else {
+			pairs = append(pairs, pair.Value.String())
+		}
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// Parameters with a default may be left out by the caller, a rest parameter
+// comes last and collects the remaining arguments into an array.
+type AstParameter struct {
+	Token      *lexing.Token
+	Span       lexing.Span
+	Identifier *AstIdentifier
+	Default    AstExpression
+	Rest       bool
+}
+
+func (parameter *AstParameter) Type() AstType {
+	return AST_PARAMETER
+}
+func (parameter *AstParameter) TokenLiteral() string {
+	return parameter.Token.Literal
+}
+func (parameter *AstParameter) GetSpan() lexing.Span {
+	return parameter.Span
+}
+func (parameter *AstParameter) String() string {
+	if parameter.Rest {
+		return "..." + parameter.Identifier.String()
+	}
+	if parameter.Default != nil {
+		return parameter.Identifier.String() + " = " + parameter.Default.String()
+	}
+	return parameter.Identifier.String()
+}
+
+// Spreads only appear as call arguments, array items and hash entries, where
+// they stand for every item of the spread value.
+type AstSpread struct {
+	Token *lexing.Token
+	Span  lexing.Span
+	Value AstExpression
+}
+
+func (spread *AstSpread) expression() {}
+func (spread *AstSpread) Type() AstType {
+	return AST_SPREAD
+}
+func (spread *AstSpread) TokenLiteral() string {
+	return spread.Token.Literal
+}
+func (spread *AstSpread) GetSpan() lexing.Span {
+	return spread.Span
+}
+func (spread *AstSpread) String() string {
+	return "..." + spread.Value.String()
+}
+
+// Slices are written a[start:end] or a?.[start:end], either bound can be
+// left out and is nil then.
+type AstSlice struct {
+	Token    *lexing.Token
+	Span     lexing.Span
+	Left     AstExpression
+	Start    AstExpression
+	End      AstExpression
+	Optional bool
+}
+
+func (slice *AstSlice) expression() {}
+func (slice *AstSlice) Type() AstType {
+	return AST_SLICE
+}
+func (slice *AstSlice) TokenLiteral() string {
+	return slice.Token.Literal
+}
+func (slice *AstSlice) GetSpan() lexing.Span {
+	return slice.Span
+}
+func (slice *AstSlice) String() string {
+	text := slice.Left.String()
+	if slice.Optional {
+		text += "?."
+	}
+	text += "["
+	if slice.Start != nil {
+		text += slice.Start.String()
+	}
+	text += ":"
+	if slice.End != nil {
+		text += slice.End.String()
+	}
+	return text + "]"
+}
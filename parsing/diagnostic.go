@@ -0,0 +1,75 @@
+package parsing
+
+import "monkey/lexing"
+
+const (
+	_ = iota
+	SEVERITY_ERROR
+	SEVERITY_WARNING
+)
+
+type Severity int
+
+func SeverityToString(severity Severity) string {
+	switch severity {
+	case SEVERITY_ERROR:
+		return "error"
+	case SEVERITY_WARNING:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	_ = iota
+	DIAGNOSTIC_ILLEGAL_TOKEN
+	DIAGNOSTIC_UNEXPECTED_TOKEN
+	DIAGNOSTIC_EXPECTED_EXPRESSION
+	DIAGNOSTIC_INTEGER_OUT_OF_RANGE
+	DIAGNOSTIC_FRACTIONAL_LITERAL
+	DIAGNOSTIC_OUTSIDE_OF_LOOP
+	DIAGNOSTIC_SHADOWED_IDENTIFIER
+	DIAGNOSTIC_ASSIGNMENT_TO_CONSTANT
+)
+
+type DiagnosticCode int
+
+func DiagnosticCodeToString(code DiagnosticCode) string {
+	switch code {
+	case DIAGNOSTIC_ILLEGAL_TOKEN:
+		return "illegal token"
+	case DIAGNOSTIC_UNEXPECTED_TOKEN:
+		return "unexpected token"
+	case DIAGNOSTIC_EXPECTED_EXPRESSION:
+		return "expected expression"
+	case DIAGNOSTIC_INTEGER_OUT_OF_RANGE:
+		return "integer out of range"
+	case DIAGNOSTIC_FRACTIONAL_LITERAL:
+		return "fractional literal"
+	case DIAGNOSTIC_OUTSIDE_OF_LOOP:
+		return "outside of loop"
+	case DIAGNOSTIC_SHADOWED_IDENTIFIER:
+		return "shadowed identifier"
+	case DIAGNOSTIC_ASSIGNMENT_TO_CONSTANT:
+		return "assignment to constant"
+	default:
+		return "unknown"
+	}
+}
+
+// Found is the token the diagnostic was reported at, Expected lists the token
+// types that would have been accepted there and is empty when the grammar did
+// not ask for a specific token.
+type Diagnostic struct {
+	Severity Severity
+	Code     DiagnosticCode
+	Span     lexing.Span
+	Message  string
+	Expected []lexing.TokenType
+	Found    *lexing.Token
+}
+
+func (diagnostic *Diagnostic) String() string {
+	return diagnostic.Span.Start.String() + ": " + diagnostic.Message
+}
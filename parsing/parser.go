@@ -1,87 +1,176 @@
 package parsing
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"monkey/lexing"
 	"slices"
 	"strconv"
+	"strings"
 )
 
 const (
 	_ = iota
 	PRECEDENCE_LOWEST
+	PRECEDENCE_CONDITIONAL
+	PRECEDENCE_COALESCE
+	PRECEDENCE_OR
+	PRECEDENCE_AND
 	PRECEDENCE_EQUALS
 	PRECEDENCE_LESS_GREATER
+	PRECEDENCE_BITWISE_OR
+	PRECEDENCE_BITWISE_XOR
+	PRECEDENCE_BITWISE_AND
+	PRECEDENCE_SHIFT
 	PRECEDENCE_SUM
 	PRECEDENCE_PRODUCT
 	PRECEDENCE_PREFIX
+	PRECEDENCE_EXPONENT
 	PRECEDENCE_INDEX
 	PRECEDENCE_CALL
 	PRECEDENCE_ASSIGNMENT
 )
 
-func getPrecedence(tokenType lexing.TokenType) int {
-	tokenTypeToPrecedence := map[lexing.TokenType]int{
-		lexing.TOKEN_EQUALS:            PRECEDENCE_EQUALS,
-		lexing.TOKEN_NOT_EQUALS:        PRECEDENCE_EQUALS,
-		lexing.TOKEN_GREATER:           PRECEDENCE_LESS_GREATER,
-		lexing.TOKEN_GREATER_OR_EQUALS: PRECEDENCE_LESS_GREATER,
-		lexing.TOKEN_LESS:              PRECEDENCE_LESS_GREATER,
-		lexing.TOKEN_LESS_OR_EQUALS:    PRECEDENCE_LESS_GREATER,
-		lexing.TOKEN_PLUS:              PRECEDENCE_SUM,
-		lexing.TOKEN_MINUS:             PRECEDENCE_SUM,
-		lexing.TOKEN_ASTERISK:          PRECEDENCE_PRODUCT,
-		lexing.TOKEN_SLASH:             PRECEDENCE_PRODUCT,
-		lexing.TOKEN_OPEN_BRACKET:      PRECEDENCE_INDEX,
-		lexing.TOKEN_OPEN_PAREN:        PRECEDENCE_CALL,
-		lexing.TOKEN_ASSIGN:            PRECEDENCE_ASSIGNMENT,
-	}
-	return tokenTypeToPrecedence[tokenType]
+type PrefixParseFunction func(parser *Parser) AstExpression
+type InfixParseFunction func(parser *Parser, left AstExpression) AstExpression
+
+var defaultPrecedences = map[lexing.TokenType]int{
+	lexing.TOKEN_QUESTION:          PRECEDENCE_CONDITIONAL,
+	lexing.TOKEN_COALESCE:          PRECEDENCE_COALESCE,
+	lexing.TOKEN_OR:                PRECEDENCE_OR,
+	lexing.TOKEN_AND:               PRECEDENCE_AND,
+	lexing.TOKEN_EQUALS:            PRECEDENCE_EQUALS,
+	lexing.TOKEN_NOT_EQUALS:        PRECEDENCE_EQUALS,
+	lexing.TOKEN_GREATER:           PRECEDENCE_LESS_GREATER,
+	lexing.TOKEN_GREATER_OR_EQUALS: PRECEDENCE_LESS_GREATER,
+	lexing.TOKEN_LESS:              PRECEDENCE_LESS_GREATER,
+	lexing.TOKEN_LESS_OR_EQUALS:    PRECEDENCE_LESS_GREATER,
+	lexing.TOKEN_PIPE:              PRECEDENCE_BITWISE_OR,
+	lexing.TOKEN_CARET:             PRECEDENCE_BITWISE_XOR,
+	lexing.TOKEN_AMPERSAND:         PRECEDENCE_BITWISE_AND,
+	lexing.TOKEN_SHIFT_LEFT:        PRECEDENCE_SHIFT,
+	lexing.TOKEN_SHIFT_RIGHT:       PRECEDENCE_SHIFT,
+	lexing.TOKEN_PLUS:              PRECEDENCE_SUM,
+	lexing.TOKEN_MINUS:             PRECEDENCE_SUM,
+	lexing.TOKEN_ASTERISK:          PRECEDENCE_PRODUCT,
+	lexing.TOKEN_SLASH:             PRECEDENCE_PRODUCT,
+	lexing.TOKEN_PERCENT:           PRECEDENCE_PRODUCT,
+	lexing.TOKEN_POWER:             PRECEDENCE_EXPONENT,
+	lexing.TOKEN_OPEN_BRACKET:      PRECEDENCE_INDEX,
+	lexing.TOKEN_QUESTION_DOT:      PRECEDENCE_INDEX,
+	lexing.TOKEN_OPEN_PAREN:        PRECEDENCE_CALL,
+	lexing.TOKEN_ASSIGN:            PRECEDENCE_ASSIGNMENT,
+}
+
+var defaultPrefixParseFunctions = map[lexing.TokenType]PrefixParseFunction{
+	lexing.TOKEN_INTEGER:       (*Parser).parseIntegerLiteral,
+	lexing.TOKEN_TRUE:          (*Parser).parseBooleanLiteral,
+	lexing.TOKEN_FALSE:         (*Parser).parseBooleanLiteral,
+	lexing.TOKEN_NULL:          (*Parser).parseNullLiteral,
+	lexing.TOKEN_IDENTIFIER:    func(parser *Parser) AstExpression { return parser.parseIdentifier() },
+	lexing.TOKEN_STRING:        func(parser *Parser) AstExpression { return parser.parseStringLiteral() },
+	lexing.TOKEN_TEMPLATE_HEAD: (*Parser).parseTemplateString,
+	lexing.TOKEN_OPEN_PAREN:    (*Parser).parseEnforcedPrecedenceExpression,
+	lexing.TOKEN_OPEN_BRACE:    (*Parser).parseHashLiteral,
+	lexing.TOKEN_OPEN_BRACKET:  (*Parser).parseArrayLiteral,
+	lexing.TOKEN_FUNCTION:      (*Parser).parseFunctionDefinition,
+	lexing.TOKEN_IF:            func(parser *Parser) AstExpression { return parser.parseIfElse() },
+	lexing.TOKEN_BANG:          (*Parser).parsePrefixExpression,
+	lexing.TOKEN_MINUS:         (*Parser).parsePrefixExpression,
+	lexing.TOKEN_TILDE:         (*Parser).parsePrefixExpression,
+}
+
+var defaultInfixParseFunctions = map[lexing.TokenType]InfixParseFunction{
+	lexing.TOKEN_QUESTION:          (*Parser).parseConditional,
+	lexing.TOKEN_COALESCE:          (*Parser).parseLogicalExpression,
+	lexing.TOKEN_OR:                (*Parser).parseLogicalExpression,
+	lexing.TOKEN_AND:               (*Parser).parseLogicalExpression,
+	lexing.TOKEN_EQUALS:            (*Parser).parseInfixExpression,
+	lexing.TOKEN_NOT_EQUALS:        (*Parser).parseInfixExpression,
+	lexing.TOKEN_GREATER:           (*Parser).parseInfixExpression,
+	lexing.TOKEN_GREATER_OR_EQUALS: (*Parser).parseInfixExpression,
+	lexing.TOKEN_LESS:              (*Parser).parseInfixExpression,
+	lexing.TOKEN_LESS_OR_EQUALS:    (*Parser).parseInfixExpression,
+	lexing.TOKEN_PIPE:              (*Parser).parseInfixExpression,
+	lexing.TOKEN_CARET:             (*Parser).parseInfixExpression,
+	lexing.TOKEN_AMPERSAND:         (*Parser).parseInfixExpression,
+	lexing.TOKEN_SHIFT_LEFT:        (*Parser).parseInfixExpression,
+	lexing.TOKEN_SHIFT_RIGHT:       (*Parser).parseInfixExpression,
+	lexing.TOKEN_PLUS:              (*Parser).parseInfixExpression,
+	lexing.TOKEN_MINUS:             (*Parser).parseInfixExpression,
+	lexing.TOKEN_ASTERISK:          (*Parser).parseInfixExpression,
+	lexing.TOKEN_SLASH:             (*Parser).parseInfixExpression,
+	lexing.TOKEN_PERCENT:           (*Parser).parseInfixExpression,
+	lexing.TOKEN_POWER:             (*Parser).parseInfixExpression,
+	lexing.TOKEN_OPEN_BRACKET:      (*Parser).parseIndex,
+	lexing.TOKEN_QUESTION_DOT:      (*Parser).parseOptionalIndex,
+	lexing.TOKEN_OPEN_PAREN:        (*Parser).parseFunctionCall,
+	lexing.TOKEN_ASSIGN:            (*Parser).parseAssignment,
 }
 
 type Parser struct {
-	tokens       []*lexing.Token
-	position     int
-	current      *lexing.Token
-	errors       []string
-	currentError string
+	lexer       *lexing.Lexer
+	current     *lexing.Token
+	previous    *lexing.Token
+	loopDepth   int
+	diagnostics []*Diagnostic
+	panicking   bool
+
+	prefixParseFunctions map[lexing.TokenType]PrefixParseFunction
+	infixParseFunctions  map[lexing.TokenType]InfixParseFunction
+	precedences          map[lexing.TokenType]int
 }
 
+// Tokens are pulled from the lexer as the parser advances.
 func NewParser(lexer *lexing.Lexer) *Parser {
-	var tokens []*lexing.Token
+	parser := &Parser{
+		lexer:   lexer,
+		current: lexer.Next(),
 
-	for {
-		token := lexer.Next()
-		tokens = append(tokens, token)
-		if token.Type == lexing.TOKEN_EOF {
-			break
-		}
+		prefixParseFunctions: maps.Clone(defaultPrefixParseFunctions),
+		infixParseFunctions:  maps.Clone(defaultInfixParseFunctions),
+		precedences:          maps.Clone(defaultPrecedences),
 	}
+	parser.skipIllegalTokens()
 
-	return &Parser{
-		tokens:   tokens,
-		position: 0,
-		current:  tokens[0],
-	}
+	return parser
 }
 
-func (parser *Parser) commitError() {
-	if parser.currentError == "" {
+// Reporting an error puts the parser in panic mode, which silences further
+// errors until it has synchronized again at the end of a statement or block.
+func (parser *Parser) error(
+	code DiagnosticCode,
+	message string,
+	expected ...lexing.TokenType,
+) {
+	if parser.panicking {
 		return
 	}
-	parser.errors = append(parser.errors, parser.currentError)
-	parser.currentError = ""
-	return
+	parser.panicking = true
+	parser.diagnostics = append(parser.diagnostics, &Diagnostic{
+		Severity: SEVERITY_ERROR,
+		Code:     code,
+		Span:     parser.current.Span,
+		Message:  message,
+		Expected: expected,
+		Found:    parser.current,
+	})
 }
 
-func (parser *Parser) error(message string) {
-	if parser.currentError == "" {
-		parser.currentError = message
-	}
+func isSynchronizingToken(tokenType lexing.TokenType) bool {
+	return tokenType == lexing.TOKEN_SEMICOLON ||
+		tokenType == lexing.TOKEN_CLOSE_BRACE ||
+		tokenType == lexing.TOKEN_EOF
 }
 
+// Finding an expected semicolon or closing brace means the grammar is back
+// in step with the source, so panic mode ends there.
 func (parser *Parser) expect(tokenTypes ...lexing.TokenType) {
 	if slices.Contains(tokenTypes, parser.current.Type) {
+		if isSynchronizingToken(parser.current.Type) {
+			parser.panicking = false
+		}
 		return
 	}
 	tokenTypesString := ""
@@ -91,96 +180,338 @@ func (parser *Parser) expect(tokenTypes ...lexing.TokenType) {
 			tokenTypesString += ", "
 		}
 	}
-	parser.error(fmt.Sprintf(
-		"Expected token of type %s. Found token %q of type %s.",
-		tokenTypesString,
-		parser.current.Literal,
-		lexing.TokenTypeToString(parser.current.Type),
-	))
+	parser.error(
+		DIAGNOSTIC_UNEXPECTED_TOKEN,
+		fmt.Sprintf(
+			"Expected token of type %s. Found token %q of type %s.",
+			tokenTypesString,
+			parser.current.Literal,
+			lexing.TokenTypeToString(parser.current.Type),
+		),
+		tokenTypes...,
+	)
+}
+
+// Skips the rest of a statement that failed to parse, stopping after its
+// semicolon or before the closing brace of the enclosing block.
+func (parser *Parser) synchronize() {
+	if !parser.panicking {
+		return
+	}
+	parser.panicking = false
+
+	for !isSynchronizingToken(parser.current.Type) {
+		parser.advance()
+	}
+	if parser.current.Type == lexing.TOKEN_SEMICOLON {
+		parser.advance()
+	}
 }
 
 func (parser *Parser) HasErrors() bool {
-	return len(parser.errors) >= 1
+	for _, diagnostic := range parser.diagnostics {
+		if diagnostic.Severity == SEVERITY_ERROR {
+			return true
+		}
+	}
+	return false
 }
 
 func (parser *Parser) GetErrors() []string {
-	return parser.errors
+	errors := []string{}
+	for _, diagnostic := range parser.diagnostics {
+		if diagnostic.Severity == SEVERITY_ERROR {
+			errors = append(errors, diagnostic.String())
+		}
+	}
+	return errors
+}
+
+func (parser *Parser) GetDiagnostics() []*Diagnostic {
+	return parser.diagnostics
+}
+
+// Registering a parse function for a token type replaces the built in one,
+// only the parser it is registered on is affected. Parse functions must not
+// return nil, ParseExpression reports a missing operand itself.
+func (parser *Parser) RegisterPrefix(
+	tokenType lexing.TokenType,
+	function PrefixParseFunction,
+) {
+	parser.prefixParseFunctions[tokenType] = function
+}
+
+func (parser *Parser) RegisterInfix(
+	tokenType lexing.TokenType,
+	precedence int,
+	function InfixParseFunction,
+) {
+	parser.infixParseFunctions[tokenType] = function
+	parser.precedences[tokenType] = precedence
+}
+
+// The methods below give registered parse functions the same view of the
+// token stream as the built in ones.
+
+func (parser *Parser) Current() *lexing.Token {
+	return parser.current
+}
+
+func (parser *Parser) Advance() {
+	parser.advance()
+}
+
+func (parser *Parser) Expect(tokenTypes ...lexing.TokenType) {
+	parser.expect(tokenTypes...)
+}
+
+func (parser *Parser) Error(code DiagnosticCode, message string) {
+	parser.error(code, message)
+}
+
+func (parser *Parser) SpanFrom(start lexing.Position) lexing.Span {
+	return parser.spanFrom(start)
+}
+
+func (parser *Parser) Precedence(tokenType lexing.TokenType) int {
+	return parser.getPrecedence(tokenType)
+}
+
+func (parser *Parser) ParseExpression(precedence int) AstExpression {
+	return parser.parseExpression(precedence)
+}
+
+func (parser *Parser) next() {
+	if parser.current.Type == lexing.TOKEN_EOF {
+		return
+	}
+	parser.current = parser.lexer.Next()
+}
+
+// Illegal tokens carry their own diagnostic from the lexer, so they are
+// reported as soon as they are reached and never seen by the grammar. The
+// construct they were part of is broken, so the parser enters panic mode to
+// keep its own follow-on errors quiet.
+func (parser *Parser) skipIllegalTokens() {
+	for parser.current.Type == lexing.TOKEN_ILLEGAL {
+		parser.panicking = true
+		parser.diagnostics = append(parser.diagnostics, &Diagnostic{
+			Severity: SEVERITY_ERROR,
+			Code:     DIAGNOSTIC_ILLEGAL_TOKEN,
+			Span:     parser.current.Span,
+			Message:  parser.current.Message,
+			Found:    parser.current,
+		})
+		parser.next()
+	}
 }
 
+// In panic mode the parser does not move past the tokens it can synchronize
+// at, so a broken construct cannot swallow the end of its statement.
 func (parser *Parser) advance() {
-	if parser.position+1 >= len(parser.tokens) {
-		parser.current = parser.tokens[len(parser.tokens)-1]
+	if parser.panicking && isSynchronizingToken(parser.current.Type) {
 		return
 	}
-	parser.position += 1
-	parser.current = parser.tokens[parser.position]
+	parser.previous = parser.current
+	parser.next()
+	parser.skipIllegalTokens()
+}
+
+func (parser *Parser) spanFrom(start lexing.Position) lexing.Span {
+	if parser.previous == nil || parser.previous.Span.End.Offset < start.Offset {
+		return lexing.Span{Start: start, End: start}
+	}
+	return lexing.Span{Start: start, End: parser.previous.Span.End}
 }
 
-func (parser *Parser) peek() *lexing.Token {
-	if parser.position+1 >= len(parser.tokens) {
-		return nil
+func spanStart(node AstNode, fallback *lexing.Token) lexing.Position {
+	if node == nil {
+		return fallback.Span.Start
 	}
-	return parser.tokens[parser.position+1]
+	return node.GetSpan().Start
 }
 
-func (parser *Parser) parseIntegerLiteral() *AstIntegerLiteral {
-	value, _ := strconv.ParseInt(parser.current.Literal, 10, 64)
+var errFractionalLiteral = errors.New("fractional literal")
+
+// parseDecimal evaluates a decimal literal with a fraction or an exponent.
+// Without a float type only literals with an integer value are accepted.
+func parseDecimal(digits string) (int64, error) {
+	mantissa, exponentText, _ := strings.Cut(strings.ToLower(digits), "e")
+	whole, fraction, _ := strings.Cut(mantissa, ".")
+	mantissa = strings.TrimLeft(whole+fraction, "0")
+	if mantissa == "" {
+		return 0, nil
+	}
+
+	exponent := 0
+	if exponentText != "" {
+		parsed, err := strconv.ParseInt(exponentText, 10, 32)
+		if err != nil {
+			if exponentText[0] == '-' {
+				return 0, errFractionalLiteral
+			}
+			return 0, strconv.ErrRange
+		}
+		exponent = int(parsed)
+	}
+	exponent -= len(fraction)
+
+	trimmed := strings.TrimRight(mantissa, "0")
+	exponent += len(mantissa) - len(trimmed)
+	if exponent < 0 {
+		return 0, errFractionalLiteral
+	}
+	// no int64 has more than 19 digits
+	if len(trimmed)+exponent > 19 {
+		return 0, strconv.ErrRange
+	}
+	return strconv.ParseInt(trimmed+strings.Repeat("0", exponent), 10, 64)
+}
+
+func parseInteger(literal string) (int64, error) {
+	digits := strings.ReplaceAll(literal, "_", "")
+	base := 10
+
+	if len(digits) > 2 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 10 {
+			digits = digits[2:]
+		}
+	}
+
+	if base == 10 && strings.ContainsAny(digits, ".eE") {
+		return parseDecimal(digits)
+	}
+	return strconv.ParseInt(digits, base, 64)
+}
+
+func (parser *Parser) parseIntegerLiteral() AstExpression {
+	value, err := parseInteger(parser.current.Literal)
+	if errors.Is(err, errFractionalLiteral) {
+		parser.error(DIAGNOSTIC_FRACTIONAL_LITERAL, fmt.Sprintf(
+			"Literal %s is not an integer, there is no float type.",
+			parser.current.Literal,
+		))
+	} else if err != nil {
+		parser.error(DIAGNOSTIC_INTEGER_OUT_OF_RANGE, fmt.Sprintf(
+			"Integer literal %s is out of range.",
+			parser.current.Literal,
+		))
+	}
 	integerLiteral := &AstIntegerLiteral{
 		Token: parser.current,
+		Span:  parser.current.Span,
 		Value: value,
 	}
 	parser.advance()
 	return integerLiteral
 }
 
-func (parser *Parser) parsePrefixExpression() *AstPrefixExpression {
+func (parser *Parser) parsePrefixExpression() AstExpression {
 	prefixExpresion := &AstPrefixExpression{
 		Token:    parser.current,
 		Operator: parser.current.Literal,
 	}
 	parser.advance()
 	prefixExpresion.Right = parser.parseExpression(PRECEDENCE_PREFIX)
+	prefixExpresion.Span = parser.spanFrom(prefixExpresion.Token.Span.Start)
 	return prefixExpresion
 }
 
-func (parser *Parser) parseInfixExpression(left AstExpression) *AstInfixExpression {
+func (parser *Parser) parseInfixExpression(left AstExpression) AstExpression {
 	infixExpression := &AstInfixExpression{
 		Token:    parser.current,
 		Left:     left,
 		Operator: parser.current.Literal,
 	}
-	precedence := getPrecedence(parser.current.Type)
+	precedence := parser.getPrecedence(parser.current.Type)
+	if parser.current.Type == lexing.TOKEN_POWER {
+		// right associative, 2 ** 3 ** 2 is 2 ** (3 ** 2)
+		precedence -= 1
+	}
 	parser.advance()
 	infixExpression.Right = parser.parseExpression(precedence)
+	infixExpression.Span = parser.spanFrom(spanStart(left, infixExpression.Token))
 	return infixExpression
 }
 
-func (parser *Parser) parseBooleanLiteral() *AstBooleanLiteral {
+func (parser *Parser) parseLogicalExpression(left AstExpression) AstExpression {
+	logicalExpression := &AstLogicalExpression{
+		Token:    parser.current,
+		Left:     left,
+		Operator: parser.current.Literal,
+	}
+	precedence := parser.getPrecedence(parser.current.Type)
+	parser.advance()
+	logicalExpression.Right = parser.parseExpression(precedence)
+	logicalExpression.Span = parser.spanFrom(spanStart(left, logicalExpression.Token))
+	return logicalExpression
+}
+
+func (parser *Parser) parseBooleanLiteral() AstExpression {
 	booleanLiteral := &AstBooleanLiteral{
 		Token: parser.current,
+		Span:  parser.current.Span,
 		Value: parser.current.Type == lexing.TOKEN_TRUE,
 	}
 	parser.advance()
 	return booleanLiteral
 }
 
+func (parser *Parser) parseNullLiteral() AstExpression {
+	nullLiteral := &AstNullLiteral{
+		Token: parser.current,
+		Span:  parser.current.Span,
+	}
+	parser.advance()
+	return nullLiteral
+}
+
+// The bad expression stands in for the missing operand so that callers never
+// see a nil expression, the token is left for the caller to recover from.
+func (parser *Parser) parseBadExpression() *AstBadExpression {
+	parser.error(DIAGNOSTIC_EXPECTED_EXPRESSION, fmt.Sprintf(
+		"Expected expression. Found token %q of type %s.",
+		parser.current.Literal,
+		lexing.TokenTypeToString(parser.current.Type),
+	))
+	return &AstBadExpression{
+		Token: parser.current,
+		Span: lexing.Span{
+			Start: parser.current.Span.Start,
+			End:   parser.current.Span.Start,
+		},
+	}
+}
+
 func (parser *Parser) parseEnforcedPrecedenceExpression() AstExpression {
 	parser.advance()
 	expression := parser.parseExpression(PRECEDENCE_LOWEST)
+
+	parser.expect(lexing.TOKEN_CLOSE_PAREN)
 	parser.advance()
+
 	return expression
 }
 
 func (parser *Parser) parseIdentifier() *AstIdentifier {
 	identifier := &AstIdentifier{
 		Token: parser.current,
+		Span:  parser.current.Span,
 		Name:  parser.current.Literal,
 	}
 	parser.advance()
 	return identifier
 }
 
-func (parser *Parser) parseFunctionCall(left AstExpression) *AstFunctionCall {
+func (parser *Parser) parseFunctionCall(left AstExpression) AstExpression {
 	functionCall := &AstFunctionCall{
 		Token:     parser.current,
 		Left:      left,
@@ -189,7 +520,7 @@ func (parser *Parser) parseFunctionCall(left AstExpression) *AstFunctionCall {
 	parser.advance()
 
 	for parser.current.Type != lexing.TOKEN_CLOSE_PAREN {
-		expression := parser.parseExpression(PRECEDENCE_LOWEST)
+		expression := parser.parseSpreadableExpression()
 		functionCall.Arguments = append(functionCall.Arguments, expression)
 		if parser.current.Type != lexing.TOKEN_CLOSE_PAREN {
 			parser.expect(lexing.TOKEN_COMMA)
@@ -205,43 +536,152 @@ func (parser *Parser) parseFunctionCall(left AstExpression) *AstFunctionCall {
 	parser.expect(lexing.TOKEN_CLOSE_PAREN)
 	parser.advance()
 
-	parser.commitError()
+	functionCall.Span = parser.spanFrom(spanStart(left, functionCall.Token))
 	return functionCall
 }
 
-func (parser *Parser) parseIndex(left AstExpression) *AstIndex {
-	index := &AstIndex{
-		Token: parser.current,
-		Left:  left,
-	}
+func (parser *Parser) parseIndex(left AstExpression) AstExpression {
+	token := parser.current
 	parser.advance()
 
-	index.Index = parser.parseExpression(PRECEDENCE_LOWEST)
+	return parser.parseSubscript(left, token, false)
+}
 
+// parseSubscript parses what follows an opening bracket, a colon after the
+// first expression, or in its place, makes it a slice.
+func (parser *Parser) parseSubscript(
+	left AstExpression,
+	token *lexing.Token,
+	optional bool,
+) AstExpression {
+	var start AstExpression
+	if parser.current.Type != lexing.TOKEN_COLON {
+		start = parser.parseExpression(PRECEDENCE_LOWEST)
+	}
+
+	if parser.current.Type != lexing.TOKEN_COLON {
+		index := &AstIndex{
+			Token:    token,
+			Left:     left,
+			Index:    start,
+			Optional: optional,
+		}
+		parser.expect(lexing.TOKEN_CLOSE_BRACKET)
+		parser.advance()
+
+		index.Span = parser.spanFrom(spanStart(left, token))
+		return index
+	}
+	parser.advance()
+
+	slice := &AstSlice{
+		Token:    token,
+		Left:     left,
+		Start:    start,
+		Optional: optional,
+	}
+	if parser.current.Type != lexing.TOKEN_CLOSE_BRACKET {
+		slice.End = parser.parseExpression(PRECEDENCE_LOWEST)
+	}
 	parser.expect(lexing.TOKEN_CLOSE_BRACKET)
 	parser.advance()
 
-	parser.commitError()
+	slice.Span = parser.spanFrom(spanStart(left, token))
+	return slice
+}
+
+func (parser *Parser) parseOptionalIndex(left AstExpression) AstExpression {
+	token := parser.current
+	parser.advance()
+
+	if parser.current.Type == lexing.TOKEN_OPEN_BRACKET {
+		parser.advance()
+
+		return parser.parseSubscript(left, token, true)
+	}
+
+	index := &AstIndex{
+		Token:    token,
+		Left:     left,
+		Optional: true,
+	}
+	parser.expect(lexing.TOKEN_IDENTIFIER)
+	index.Index = &AstStringLiteral{
+		Token: parser.current,
+		Span:  parser.current.Span,
+		Value: parser.current.Literal,
+	}
+	parser.advance()
+
+	index.Span = parser.spanFrom(spanStart(left, index.Token))
 	return index
 }
 
 func (parser *Parser) parseStringLiteral() *AstStringLiteral {
 	stringLiteral := &AstStringLiteral{
 		Token: parser.current,
+		Span:  parser.current.Span,
 		Value: parser.current.Literal,
 	}
 	parser.advance()
 	return stringLiteral
 }
 
-func (parser *Parser) parseArrayLiteral() *AstArrayLiteral {
+func (parser *Parser) parseTemplateString() AstExpression {
+	templateString := &AstTemplateString{
+		Token: parser.current,
+		Parts: []AstExpression{parser.parseStringLiteral()},
+	}
+
+	for {
+		expression := parser.parseExpression(PRECEDENCE_LOWEST)
+		templateString.Parts = append(templateString.Parts, expression)
+
+		parser.expect(lexing.TOKEN_TEMPLATE_MIDDLE, lexing.TOKEN_TEMPLATE_TAIL)
+		if parser.current.Type != lexing.TOKEN_TEMPLATE_MIDDLE &&
+			parser.current.Type != lexing.TOKEN_TEMPLATE_TAIL {
+			break
+		}
+
+		done := parser.current.Type == lexing.TOKEN_TEMPLATE_TAIL
+		templateString.Parts = append(templateString.Parts, parser.parseStringLiteral())
+		if done {
+			break
+		}
+	}
+
+	templateString.Span = parser.spanFrom(templateString.Token.Span.Start)
+	return templateString
+}
+
+func (parser *Parser) parseSpread() *AstSpread {
+	spread := &AstSpread{
+		Token: parser.current,
+	}
+	parser.advance()
+
+	spread.Value = parser.parseExpression(PRECEDENCE_LOWEST)
+	spread.Span = parser.spanFrom(spread.Token.Span.Start)
+	return spread
+}
+
+// Spreads are only accepted where a list of items is expected, they are not
+// expressions on their own.
+func (parser *Parser) parseSpreadableExpression() AstExpression {
+	if parser.current.Type == lexing.TOKEN_ELLIPSIS {
+		return parser.parseSpread()
+	}
+	return parser.parseExpression(PRECEDENCE_LOWEST)
+}
+
+func (parser *Parser) parseArrayLiteral() AstExpression {
 	arrayLiteral := &AstArrayLiteral{
 		Token: parser.current,
 		Items: []AstExpression{},
 	}
 	parser.advance()
 	for parser.current.Type != lexing.TOKEN_CLOSE_BRACKET {
-		expression := parser.parseExpression(PRECEDENCE_LOWEST)
+		expression := parser.parseSpreadableExpression()
 		arrayLiteral.Items = append(arrayLiteral.Items, expression)
 
 		if parser.current.Type != lexing.TOKEN_CLOSE_BRACKET {
@@ -258,28 +698,34 @@ func (parser *Parser) parseArrayLiteral() *AstArrayLiteral {
 	parser.expect(lexing.TOKEN_CLOSE_BRACKET)
 	parser.advance()
 
-	parser.commitError()
+	arrayLiteral.Span = parser.spanFrom(arrayLiteral.Token.Span.Start)
 	return arrayLiteral
 }
 
-func (parser *Parser) parseHashLiteral() *AstHashLiteral {
+func (parser *Parser) parseHashLiteral() AstExpression {
 	hashLiteral := &AstHashLiteral{
 		Token: parser.current,
 		Pairs: []*AstHashLiteralPair{},
 	}
 	parser.advance()
 	for parser.current.Type != lexing.TOKEN_CLOSE_BRACE {
-		key := parser.parseExpression(PRECEDENCE_LOWEST)
-
-		parser.expect(lexing.TOKEN_COLON)
-		parser.advance()
-
-		value := parser.parseExpression(PRECEDENCE_LOWEST)
-
-		hashLiteral.Pairs = append(hashLiteral.Pairs, &AstHashLiteralPair{
-			Key:   key,
-			Value: value,
-		})
+		if parser.current.Type == lexing.TOKEN_ELLIPSIS {
+			hashLiteral.Pairs = append(hashLiteral.Pairs, &AstHashLiteralPair{
+				Key: parser.parseSpread(),
+			})
+		} else {
+			key := parser.parseExpression(PRECEDENCE_LOWEST)
+
+			parser.expect(lexing.TOKEN_COLON)
+			parser.advance()
+
+			value := parser.parseExpression(PRECEDENCE_LOWEST)
+
+			hashLiteral.Pairs = append(hashLiteral.Pairs, &AstHashLiteralPair{
+				Key:   key,
+				Value: value,
+			})
+		}
 
 		if parser.current.Type != lexing.TOKEN_CLOSE_BRACE {
 			parser.expect(lexing.TOKEN_COMMA)
@@ -295,7 +741,7 @@ func (parser *Parser) parseHashLiteral() *AstHashLiteral {
 	parser.expect(lexing.TOKEN_CLOSE_BRACE)
 	parser.advance()
 
-	parser.commitError()
+	hashLiteral.Span = parser.spanFrom(hashLiteral.Token.Span.Start)
 	return hashLiteral
 }
 
@@ -311,26 +757,55 @@ func (parser *Parser) parseCompound() *AstCompound {
 			compound.Statements,
 			parser.parseStatement(),
 		)
+		parser.synchronize()
 	}
+	compound.Span = parser.spanFrom(compound.Token.Span.Start)
 
 	return compound
 }
 
-func (parser *Parser) parseFunctionDefinition() *AstFunctionDefinition {
+func (parser *Parser) parseParameter() *AstParameter {
+	parameter := &AstParameter{
+		Token: parser.current,
+	}
+	if parser.current.Type == lexing.TOKEN_ELLIPSIS {
+		parameter.Rest = true
+		parser.advance()
+	}
+
+	parser.expect(lexing.TOKEN_IDENTIFIER)
+	parameter.Identifier = parser.parseIdentifier()
+
+	if !parameter.Rest && parser.current.Type == lexing.TOKEN_ASSIGN {
+		parser.advance()
+
+		parameter.Default = parser.parseExpression(PRECEDENCE_LOWEST)
+	}
+
+	parameter.Span = parser.spanFrom(parameter.Token.Span.Start)
+	return parameter
+}
+
+func (parser *Parser) parseFunctionDefinition() AstExpression {
 	functionDefinition := &AstFunctionDefinition{
 		Token:      parser.current,
-		Parameters: []*AstIdentifier{},
+		Parameters: []*AstParameter{},
 	}
-
 	parser.advance()
+
+	parser.expect(lexing.TOKEN_OPEN_PAREN)
 	parser.advance()
 
 	for parser.current.Type != lexing.TOKEN_CLOSE_PAREN {
-		parser.expect(lexing.TOKEN_IDENTIFIER)
+		parameter := parser.parseParameter()
 		functionDefinition.Parameters = append(
 			functionDefinition.Parameters,
-			parser.parseIdentifier(),
+			parameter,
 		)
+		// the closing paren is expected right after a rest parameter
+		if parameter.Rest {
+			break
+		}
 
 		if parser.current.Type != lexing.TOKEN_CLOSE_PAREN {
 			parser.expect(lexing.TOKEN_COMMA)
@@ -349,12 +824,15 @@ func (parser *Parser) parseFunctionDefinition() *AstFunctionDefinition {
 	parser.expect(lexing.TOKEN_OPEN_BRACE)
 	parser.advance()
 
+	loopDepth := parser.loopDepth
+	parser.loopDepth = 0
 	functionDefinition.Body = parser.parseCompound()
+	parser.loopDepth = loopDepth
 
 	parser.expect(lexing.TOKEN_CLOSE_BRACE)
 	parser.advance()
 
-	parser.commitError()
+	functionDefinition.Span = parser.spanFrom(functionDefinition.Token.Span.Start)
 	return functionDefinition
 }
 
@@ -381,12 +859,18 @@ func (parser *Parser) parseIfElse() *AstIfElse {
 	parser.advance()
 
 	if parser.current.Type != lexing.TOKEN_ELSE {
-		parser.commitError()
+		ifElse.Span = parser.spanFrom(ifElse.Token.Span.Start)
 		return ifElse
 	}
 
 	parser.advance()
 
+	if parser.current.Type == lexing.TOKEN_IF {
+		ifElse.ElseIf = parser.parseIfElse()
+		ifElse.Span = parser.spanFrom(ifElse.Token.Span.Start)
+		return ifElse
+	}
+
 	parser.expect(lexing.TOKEN_OPEN_BRACE)
 	parser.advance()
 
@@ -395,59 +879,56 @@ func (parser *Parser) parseIfElse() *AstIfElse {
 	parser.expect(lexing.TOKEN_CLOSE_BRACE)
 	parser.advance()
 
-	parser.commitError()
+	ifElse.Span = parser.spanFrom(ifElse.Token.Span.Start)
 	return ifElse
 }
 
-func (parser *Parser) parseAssignment(left AstExpression) *AstAssignment {
+func (parser *Parser) parseConditional(left AstExpression) AstExpression {
+	conditional := &AstConditional{
+		Token:     parser.current,
+		Condition: left,
+	}
+	parser.advance()
+
+	conditional.Then = parser.parseExpression(PRECEDENCE_LOWEST)
+
+	parser.expect(lexing.TOKEN_COLON)
+	parser.advance()
+
+	// right associative, a ? b : c ? d : e is a ? b : (c ? d : e)
+	conditional.Else = parser.parseExpression(PRECEDENCE_CONDITIONAL - 1)
+	conditional.Span = parser.spanFrom(spanStart(left, conditional.Token))
+	return conditional
+}
+
+func (parser *Parser) parseAssignment(left AstExpression) AstExpression {
 	assignment := &AstAssignment{
 		Token: parser.current,
 		Left:  left,
 	}
 	parser.advance()
 	assignment.Value = parser.parseExpression(PRECEDENCE_LOWEST)
-
-	parser.commitError()
+	assignment.Span = parser.spanFrom(spanStart(left, assignment.Token))
 	return assignment
 }
 
+func (parser *Parser) getPrecedence(tokenType lexing.TokenType) int {
+	return parser.precedences[tokenType]
+}
+
 func (parser *Parser) parseExpression(precedence int) AstExpression {
 	var left AstExpression
 
-	switch parser.current.Type {
-	case lexing.TOKEN_INTEGER:
-		left = parser.parseIntegerLiteral()
-	case lexing.TOKEN_TRUE, lexing.TOKEN_FALSE:
-		left = parser.parseBooleanLiteral()
-	case lexing.TOKEN_IDENTIFIER:
-		left = parser.parseIdentifier()
-	case lexing.TOKEN_STRING:
-		left = parser.parseStringLiteral()
-	case lexing.TOKEN_OPEN_PAREN:
-		left = parser.parseEnforcedPrecedenceExpression()
-	case lexing.TOKEN_OPEN_BRACE:
-		left = parser.parseHashLiteral()
-	case lexing.TOKEN_OPEN_BRACKET:
-		left = parser.parseArrayLiteral()
-	case lexing.TOKEN_FUNCTION:
-		left = parser.parseFunctionDefinition()
-	case lexing.TOKEN_IF:
-		left = parser.parseIfElse()
-	case lexing.TOKEN_BANG, lexing.TOKEN_MINUS:
-		left = parser.parsePrefixExpression()
-	}
-
-	for precedence < getPrecedence(parser.current.Type) {
-		switch parser.current.Type {
-		case lexing.TOKEN_OPEN_PAREN:
-			left = parser.parseFunctionCall(left)
-		case lexing.TOKEN_OPEN_BRACKET:
-			left = parser.parseIndex(left)
-		case lexing.TOKEN_ASSIGN:
-			left = parser.parseAssignment(left)
-		default:
-			left = parser.parseInfixExpression(left)
-		}
+	prefix, ok := parser.prefixParseFunctions[parser.current.Type]
+	if ok {
+		left = prefix(parser)
+	} else {
+		left = parser.parseBadExpression()
+	}
+
+	for precedence < parser.getPrecedence(parser.current.Type) {
+		infix := parser.infixParseFunctions[parser.current.Type]
+		left = infix(parser, left)
 	}
 
 	return left
@@ -462,70 +943,288 @@ func (parser *Parser) parseExpressionStatement() *AstExpressionStatement {
 	parser.expect(lexing.TOKEN_SEMICOLON)
 	parser.advance()
 
-	parser.commitError()
+	expressionStatement.Span = parser.spanFrom(expressionStatement.Token.Span.Start)
 	return expressionStatement
 }
 
 func (parser *Parser) parseLetStatement() *AstLetStatement {
 	letStatement := &AstLetStatement{
-		Token: parser.current,
+		Token:    parser.current,
+		Constant: parser.current.Type == lexing.TOKEN_CONST,
 	}
 	parser.advance()
 
-	letStatement.Identifier = parser.parseIdentifier()
+	switch parser.current.Type {
+	case lexing.TOKEN_OPEN_BRACKET:
+		letStatement.Pattern = parser.parseArrayPattern()
+	case lexing.TOKEN_OPEN_BRACE:
+		letStatement.Pattern = parser.parseHashPattern()
+	default:
+		parser.expect(lexing.TOKEN_IDENTIFIER)
+		letStatement.Identifier = parser.parseIdentifier()
+	}
 
-	if parser.current.Type == lexing.TOKEN_ASSIGN {
+	// only a plain identifier can be declared without a value, and only by let
+	if parser.current.Type == lexing.TOKEN_ASSIGN ||
+		letStatement.Pattern != nil ||
+		letStatement.Constant {
+		parser.expect(lexing.TOKEN_ASSIGN)
 		parser.advance()
 
 		letStatement.Value = parser.parseExpression(PRECEDENCE_LOWEST)
-
-		if letStatement.Value == nil {
-			parser.error(fmt.Sprintf(
-				"Expected expression. Found token %q of type %s.",
-				parser.current.Literal,
-				lexing.TokenTypeToString(parser.current.Type),
-			))
-		}
 	}
 
 	parser.expect(lexing.TOKEN_SEMICOLON)
 	parser.advance()
 
-	parser.commitError()
+	letStatement.Span = parser.spanFrom(letStatement.Token.Span.Start)
 	return letStatement
 }
 
+func (parser *Parser) parseArrayPattern() *AstArrayPattern {
+	arrayPattern := &AstArrayPattern{
+		Token:    parser.current,
+		Elements: []*AstIdentifier{},
+	}
+	parser.advance()
+
+	for parser.current.Type != lexing.TOKEN_CLOSE_BRACKET {
+		// the rest identifier has to be last, the closing bracket is
+		// expected right after it
+		if parser.current.Type == lexing.TOKEN_ELLIPSIS {
+			parser.advance()
+
+			parser.expect(lexing.TOKEN_IDENTIFIER)
+			arrayPattern.Rest = parser.parseIdentifier()
+			break
+		}
+
+		parser.expect(lexing.TOKEN_IDENTIFIER)
+		arrayPattern.Elements = append(
+			arrayPattern.Elements,
+			parser.parseIdentifier(),
+		)
+
+		if parser.current.Type != lexing.TOKEN_CLOSE_BRACKET {
+			parser.expect(lexing.TOKEN_COMMA)
+			if parser.current.Type == lexing.TOKEN_COMMA {
+				parser.advance()
+			} else {
+				parser.advance()
+				break
+			}
+		}
+	}
+
+	parser.expect(lexing.TOKEN_CLOSE_BRACKET)
+	parser.advance()
+
+	arrayPattern.Span = parser.spanFrom(arrayPattern.Token.Span.Start)
+	return arrayPattern
+}
+
+func (parser *Parser) parseHashPatternPair() *AstHashPatternPair {
+	parser.expect(lexing.TOKEN_IDENTIFIER, lexing.TOKEN_STRING)
+	pair := &AstHashPatternPair{
+		Key: parser.parseStringLiteral(),
+	}
+
+	if parser.current.Type == lexing.TOKEN_COLON ||
+		pair.Key.Token.Type != lexing.TOKEN_IDENTIFIER {
+		parser.expect(lexing.TOKEN_COLON)
+		parser.advance()
+
+		parser.expect(lexing.TOKEN_IDENTIFIER)
+		pair.Value = parser.parseIdentifier()
+	} else {
+		pair.Value = &AstIdentifier{
+			Token: pair.Key.Token,
+			Span:  pair.Key.Span,
+			Name:  pair.Key.Value,
+		}
+	}
+
+	return pair
+}
+
+func (parser *Parser) parseHashPattern() *AstHashPattern {
+	hashPattern := &AstHashPattern{
+		Token: parser.current,
+		Pairs: []*AstHashPatternPair{},
+	}
+	parser.advance()
+
+	for parser.current.Type != lexing.TOKEN_CLOSE_BRACE {
+		hashPattern.Pairs = append(
+			hashPattern.Pairs,
+			parser.parseHashPatternPair(),
+		)
+
+		if parser.current.Type != lexing.TOKEN_CLOSE_BRACE {
+			parser.expect(lexing.TOKEN_COMMA)
+			if parser.current.Type == lexing.TOKEN_COMMA {
+				parser.advance()
+			} else {
+				parser.advance()
+				break
+			}
+		}
+	}
+
+	parser.expect(lexing.TOKEN_CLOSE_BRACE)
+	parser.advance()
+
+	hashPattern.Span = parser.spanFrom(hashPattern.Token.Span.Start)
+	return hashPattern
+}
+
 func (parser *Parser) parseReturnStatement() *AstReturnStatement {
 	returnStatement := &AstReturnStatement{
 		Token: parser.current,
 	}
 	parser.advance()
 
-	returnStatement.Value = parser.parseExpression(PRECEDENCE_LOWEST)
+	if parser.current.Type != lexing.TOKEN_SEMICOLON {
+		returnStatement.Value = parser.parseExpression(PRECEDENCE_LOWEST)
+	}
 
 	parser.expect(lexing.TOKEN_SEMICOLON)
 	parser.advance()
 
-	parser.commitError()
+	returnStatement.Span = parser.spanFrom(returnStatement.Token.Span.Start)
 	return returnStatement
 }
 
+// Loop bodies are the only statements that do not need a semicolon, one
+// is still accepted to match if-else expressions.
+func (parser *Parser) parseLoopBody() *AstCompound {
+	parser.expect(lexing.TOKEN_OPEN_BRACE)
+	parser.advance()
+
+	parser.loopDepth += 1
+	body := parser.parseCompound()
+	parser.loopDepth -= 1
+
+	parser.expect(lexing.TOKEN_CLOSE_BRACE)
+	parser.advance()
+
+	if parser.current.Type == lexing.TOKEN_SEMICOLON {
+		parser.advance()
+	}
+
+	return body
+}
+
+func (parser *Parser) parseWhileStatement() *AstWhileStatement {
+	whileStatement := &AstWhileStatement{
+		Token: parser.current,
+	}
+	parser.advance()
+
+	parser.expect(lexing.TOKEN_OPEN_PAREN)
+	parser.advance()
+
+	whileStatement.Condition = parser.parseExpression(PRECEDENCE_LOWEST)
+
+	parser.expect(lexing.TOKEN_CLOSE_PAREN)
+	parser.advance()
+
+	whileStatement.Body = parser.parseLoopBody()
+	whileStatement.Span = parser.spanFrom(whileStatement.Token.Span.Start)
+	return whileStatement
+}
+
+func (parser *Parser) parseForStatement() *AstForStatement {
+	forStatement := &AstForStatement{
+		Token: parser.current,
+	}
+	parser.advance()
+
+	parser.expect(lexing.TOKEN_OPEN_PAREN)
+	parser.advance()
+
+	parser.expect(lexing.TOKEN_IDENTIFIER)
+	forStatement.Identifier = parser.parseIdentifier()
+
+	parser.expect(lexing.TOKEN_IN)
+	parser.advance()
+
+	forStatement.Iterable = parser.parseExpression(PRECEDENCE_LOWEST)
+
+	parser.expect(lexing.TOKEN_CLOSE_PAREN)
+	parser.advance()
+
+	forStatement.Body = parser.parseLoopBody()
+	forStatement.Span = parser.spanFrom(forStatement.Token.Span.Start)
+	return forStatement
+}
+
+func (parser *Parser) parseLoopControl() {
+	if parser.loopDepth == 0 {
+		parser.error(DIAGNOSTIC_OUTSIDE_OF_LOOP, fmt.Sprintf(
+			"Unexpected %s outside of a loop.",
+			parser.current.Literal,
+		))
+	}
+	parser.advance()
+
+	parser.expect(lexing.TOKEN_SEMICOLON)
+	parser.advance()
+}
+
+func (parser *Parser) parseBreakStatement() *AstBreakStatement {
+	breakStatement := &AstBreakStatement{
+		Token: parser.current,
+	}
+	parser.parseLoopControl()
+	breakStatement.Span = parser.spanFrom(breakStatement.Token.Span.Start)
+	return breakStatement
+}
+
+func (parser *Parser) parseContinueStatement() *AstContinueStatement {
+	continueStatement := &AstContinueStatement{
+		Token: parser.current,
+	}
+	parser.parseLoopControl()
+	continueStatement.Span = parser.spanFrom(continueStatement.Token.Span.Start)
+	return continueStatement
+}
+
 func (parser *Parser) parseStatement() AstStatement {
 	switch parser.current.Type {
-	case lexing.TOKEN_LET:
+	case lexing.TOKEN_LET, lexing.TOKEN_CONST:
 		return parser.parseLetStatement()
 	case lexing.TOKEN_RETURN:
 		return parser.parseReturnStatement()
+	case lexing.TOKEN_WHILE:
+		return parser.parseWhileStatement()
+	case lexing.TOKEN_FOR:
+		return parser.parseForStatement()
+	case lexing.TOKEN_BREAK:
+		return parser.parseBreakStatement()
+	case lexing.TOKEN_CONTINUE:
+		return parser.parseContinueStatement()
 	default:
 		return parser.parseExpressionStatement()
 	}
 }
 
+// A closing brace without a block to close ends the top level early, it is
+// reported and skipped so that the rest of the source is still parsed.
 func (parser *Parser) Parse() *AstCompound {
 	compound := parser.parseCompound()
 
-	parser.expect(lexing.TOKEN_EOF)
+	for parser.current.Type != lexing.TOKEN_EOF {
+		parser.expect(lexing.TOKEN_EOF)
+		parser.panicking = false
+		parser.advance()
+
+		compound.Statements = append(
+			compound.Statements,
+			parser.parseCompound().Statements...,
+		)
+	}
+	compound.Span = parser.spanFrom(compound.Token.Span.Start)
 
-	parser.commitError()
 	return compound
 }
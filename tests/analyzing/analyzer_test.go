@@ -0,0 +1,103 @@
+package analyzing_test
+
+import (
+	"monkey/analyzing"
+	"monkey/lexing"
+	"monkey/parsing"
+	"testing"
+)
+
+func TestAnalyzeShadowing(t *testing.T) {
+	expectations := []struct {
+		input    string
+		warnings []string
+	}{
+		{"let a = 1; let f = fn (a) { a; };", []string{`1:24: Identifier "a" shadows a declaration at 1:5.`}},
+		{"let a = 1; if (true) { let a = 2; };", []string{`1:28: Identifier "a" shadows a declaration at 1:5.`}},
+		{"let x = 1; for (x in [1]) { x; }", []string{`1:17: Identifier "x" shadows a declaration at 1:5.`}},
+		{"let a = 1; fn () { let [b, ...a] = [1]; };", []string{`1:31: Identifier "a" shadows a declaration at 1:5.`}},
+		{"fn (a) { let a = 1; };", []string{`1:14: Identifier "a" shadows a declaration at 1:5.`}},
+		{"let a = 1; let b = fn (c) { let d = c; };", []string{}},
+		{"if (true) { let a = 1; }; let a = 2;", []string{}},
+		{"let a = 1; let a = 2;", []string{}},
+	}
+
+	for _, expectation := range expectations {
+		lexer := lexing.NewLexer(expectation.input)
+		parser := parsing.NewParser(lexer)
+		ast := parser.Parse()
+		if parser.HasErrors() {
+			t.Fatalf("Unexpected parser errors for %q: %v", expectation.input, parser.GetErrors())
+		}
+
+		analyzer := analyzing.NewAnalyzer(analyzing.Options{WarnShadowing: true})
+		diagnostics := analyzer.Analyze(ast)
+		if len(diagnostics) != len(expectation.warnings) {
+			t.Fatalf("Expected %d warnings for %q, got %d.", len(expectation.warnings), expectation.input, len(diagnostics))
+		}
+		for i, diagnostic := range diagnostics {
+			if diagnostic.Severity != parsing.SEVERITY_WARNING {
+				t.Fatalf("Expected severity warning, got %s.", parsing.SeverityToString(diagnostic.Severity))
+			}
+			if diagnostic.Code != parsing.DIAGNOSTIC_SHADOWED_IDENTIFIER {
+				t.Fatalf("Expected code shadowed identifier, got %s.", parsing.DiagnosticCodeToString(diagnostic.Code))
+			}
+			if diagnostic.String() != expectation.warnings[i] {
+				t.Fatalf("Expected %q, got %q.", expectation.warnings[i], diagnostic.String())
+			}
+		}
+	}
+}
+
+func TestAnalyzeShadowingDisabled(t *testing.T) {
+	lexer := lexing.NewLexer("let a = 1; let f = fn (a) { a; };")
+	parser := parsing.NewParser(lexer)
+	ast := parser.Parse()
+
+	analyzer := analyzing.NewAnalyzer(analyzing.Options{})
+	if diagnostics := analyzer.Analyze(ast); len(diagnostics) != 0 {
+		t.Fatalf("Expected no diagnostics, got %d.", len(diagnostics))
+	}
+}
+
+func TestAnalyzeConstantAssignment(t *testing.T) {
+	expectations := []struct {
+		input  string
+		errors []string
+	}{
+		{"const a = 1; a = 2;", []string{`1:14: Cannot assign to constant "a" declared at 1:7.`}},
+		{"const [a, b] = xs; let f = fn () { b = 1; };", []string{`1:36: Cannot assign to constant "b" declared at 1:11.`}},
+		{"const a = 1; if (true) { let a = 2; a = 3; };", []string{}},
+		{"let a = 1; if (true) { const a = 2; }; a = 3;", []string{}},
+		{"const h = {}; h[\"a\"] = 1;", []string{}},
+		{"const x = 1; let f = fn () { let g = fn () { x = 2; }; let x = 3; g(); x; }; puts(f());", []string{}},
+		{"const x = 1; let f = fn () { if (true) { let g = fn () { x = 2; }; }; if (true) { let x = 3; }; };", []string{`1:58: Cannot assign to constant "x" declared at 1:7.`}},
+		{"const x = 1; let f = fn () { let g = fn () { x = 2; }; }; let y = fn () { x = 3; };", []string{`1:46: Cannot assign to constant "x" declared at 1:7.`, `1:75: Cannot assign to constant "x" declared at 1:7.`}},
+	}
+
+	for _, expectation := range expectations {
+		lexer := lexing.NewLexer(expectation.input)
+		parser := parsing.NewParser(lexer)
+		ast := parser.Parse()
+		if parser.HasErrors() {
+			t.Fatalf("Unexpected parser errors for %q: %v", expectation.input, parser.GetErrors())
+		}
+
+		analyzer := analyzing.NewAnalyzer(analyzing.Options{})
+		diagnostics := analyzer.Analyze(ast)
+		if len(diagnostics) != len(expectation.errors) {
+			t.Fatalf("Expected %d errors for %q, got %d.", len(expectation.errors), expectation.input, len(diagnostics))
+		}
+		for i, diagnostic := range diagnostics {
+			if diagnostic.Severity != parsing.SEVERITY_ERROR {
+				t.Fatalf("Expected severity error, got %s.", parsing.SeverityToString(diagnostic.Severity))
+			}
+			if diagnostic.Code != parsing.DIAGNOSTIC_ASSIGNMENT_TO_CONSTANT {
+				t.Fatalf("Expected code assignment to constant, got %s.", parsing.DiagnosticCodeToString(diagnostic.Code))
+			}
+			if diagnostic.String() != expectation.errors[i] {
+				t.Fatalf("Expected %q, got %q.", expectation.errors[i], diagnostic.String())
+			}
+		}
+	}
+}
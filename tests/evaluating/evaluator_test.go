@@ -4,6 +4,7 @@ import (
 	"monkey/evaluating"
 	"monkey/lexing"
 	"monkey/parsing"
+	"strings"
 	"testing"
 )
 
@@ -19,6 +20,38 @@ func TestEvalExpressions(t *testing.T) {
 		{"!2 == true;", evaluating.OBJECT_BOOLEAN, false},
 		{"5 / 2;", evaluating.OBJECT_INTEGER, 2},
 		{"1 + 7 * 2;", evaluating.OBJECT_INTEGER, 15},
+		{"0xFF + 0b1 - 0o10 * 1_000;", evaluating.OBJECT_INTEGER, -7744},
+		{"1.5e3 / 1e2 + 9.223372036854775807e18 % 10;", evaluating.OBJECT_INTEGER, 22},
+		{"17 % 5;", evaluating.OBJECT_INTEGER, 2},
+		{"-17 % 5;", evaluating.OBJECT_INTEGER, -2},
+		{"2 ** 10;", evaluating.OBJECT_INTEGER, 1024},
+		{"2 ** 3 ** 2;", evaluating.OBJECT_INTEGER, 512},
+		{"(-2) ** 63;", evaluating.OBJECT_INTEGER, -9223372036854775808},
+		{"7 ** 0;", evaluating.OBJECT_INTEGER, 1},
+		{"0b1100 & 0b1010;", evaluating.OBJECT_INTEGER, 8},
+		{"0b1100 | 0b1010;", evaluating.OBJECT_INTEGER, 14},
+		{"0b1100 ^ 0b1010;", evaluating.OBJECT_INTEGER, 6},
+		{"~0;", evaluating.OBJECT_INTEGER, -1},
+		{"1 << 62;", evaluating.OBJECT_INTEGER, 4611686018427387904},
+		{"-1 << 63;", evaluating.OBJECT_INTEGER, -9223372036854775808},
+		{"-16 >> 2;", evaluating.OBJECT_INTEGER, -4},
+		{"1 >> 100;", evaluating.OBJECT_INTEGER, 0},
+		{"let i = 0; while (i < 100000) { i = i + 1; } i;", evaluating.OBJECT_INTEGER, 100000},
+		{"let i = 0; while (true) { i = i + 1; if (i == 5) { break; }; } i;", evaluating.OBJECT_INTEGER, 5},
+		{"let sum = 0; for (x in [1, 2, 3, 4]) { if (x % 2 == 0) { continue; }; sum = sum + x; } sum;", evaluating.OBJECT_INTEGER, 4},
+		{"let keys = \"\"; for (key in {\"a\": 1, \"b\": 2}) { keys = keys + key; } keys;", evaluating.OBJECT_STRING, `"ab"`},
+		{"let reversed = \"\"; for (c in \"héllo\") { reversed = c + reversed; } reversed;", evaluating.OBJECT_STRING, `"olléh"`},
+		{"let find = fn (xs) { for (x in xs) { if (x > 2) { return x; }; } return -1; }; find([1, 5, 3]);", evaluating.OBJECT_INTEGER, 5},
+		{"let a = 1; let f = fn (a) { a * 10; }; f(2) + a;", evaluating.OBJECT_INTEGER, 21},
+		{"let a = 1; if (true) { let a = 2; a = 3; }; a;", evaluating.OBJECT_INTEGER, 1},
+		{"let a = 1; if (true) { a = 2; }; a;", evaluating.OBJECT_INTEGER, 2},
+		{"const limit = 10; limit * 2;", evaluating.OBJECT_INTEGER, 20},
+		{"const a = 1; if (true) { let a = 2; a = 3; }; a;", evaluating.OBJECT_INTEGER, 1},
+		{"const config = {\"debug\": false}; config[\"debug\"] = true; config[\"debug\"];", evaluating.OBJECT_BOOLEAN, true},
+		{"let x = 0; for (x in [1, 2]) { x; } x;", evaluating.OBJECT_INTEGER, 0},
+		{"let counter = fn () { let n = 0; fn () { n = n + 1; n; }; }; let next = counter(); next(); next();", evaluating.OBJECT_INTEGER, 2},
+		{"let n = 0; for (a in [1, 2]) { for (b in [1, 2, 3]) { if (b == 2) { break; }; n = n + 1; } } n;", evaluating.OBJECT_INTEGER, 2},
+		{"while (false) { 1; }", evaluating.OBJECT_NULL, nil},
 		{"let a = 2;", evaluating.OBJECT_NULL, nil},
 		{"let a = true; a;", evaluating.OBJECT_BOOLEAN, true},
 		{"fn (a, b) { return a + b; };", evaluating.OBJECT_FUNCTION, "fn (a, b)"},
@@ -26,12 +59,84 @@ func TestEvalExpressions(t *testing.T) {
 		{"fn (a) { return fn (b) { return a + b; }; }(2)(1);", evaluating.OBJECT_INTEGER, 3},
 		{"[!2, 4 + 8, true, false];", evaluating.OBJECT_ARRAY, "[false, 12, true, false]"},
 		{"\"Hello, \" + \"World!\";", evaluating.OBJECT_STRING, "\"Hello, World!\""},
+		{`"tab\t" + "quote\"";`, evaluating.OBJECT_STRING, `"tab\tquote\""`},
+		{"`C:\\path\n`;", evaluating.OBJECT_STRING, `"C:\\path\n"`},
+		{`let xs = [1, 2]; "xs: ${xs}, first: ${xs[0] + 1}, ok: ${true}, none: ${xs[5]}";`, evaluating.OBJECT_STRING, `"xs: [1, 2], first: 2, ok: true, none: null"`},
 		{"{4 - 2: false, !0: true, \"hello\": \"world\"}", evaluating.OBJECT_HASH, "{2: false, true: true, \"hello\": \"world\"}"},
 		{"{4 - 2: false, !0: true, \"hello\": \"world\"}[\"hello\"]", evaluating.OBJECT_STRING, "\"world\""},
 		{"if (1 > 0) { true; } else { false; };", evaluating.OBJECT_BOOLEAN, true},
 		{"if (1 < 0) { true; } else { false; };", evaluating.OBJECT_BOOLEAN, false},
 		{"let a; a = 10;", evaluating.OBJECT_INTEGER, 10},
 		{"let b = true; b = 80; b;", evaluating.OBJECT_INTEGER, 80},
+		{"1 < 2 && 2 < 3;", evaluating.OBJECT_BOOLEAN, true},
+		{"1 > 2 || 0;", evaluating.OBJECT_BOOLEAN, false},
+		{"false && missing;", evaluating.OBJECT_BOOLEAN, false},
+		{"\"yes\" || missing;", evaluating.OBJECT_BOOLEAN, true},
+		{"let calls = 0; let f = fn () { calls = calls + 1; return true; }; f() || f(); true && f(); calls;", evaluating.OBJECT_INTEGER, 2},
+		{"let sign = fn (x) { if (x < 0) { -1; } else if (x == 0) { 0; } else { 1; }; }; [sign(-5), sign(0), sign(7)];", evaluating.OBJECT_ARRAY, "[-1, 0, 1]"},
+		{"if (false) { 1; } else if (false) { 2; };", evaluating.OBJECT_NULL, nil},
+		{"[1, 2, 3][-1];", evaluating.OBJECT_INTEGER, 3},
+		{"[1, 2, 3][-4];", evaluating.OBJECT_NULL, nil},
+		{"let a = [1, 2, 3]; a[-1] = 4; a;", evaluating.OBJECT_ARRAY, "[1, 2, 4]"},
+		{"let a = [[1, 2]]; a[0][1] = 5; a;", evaluating.OBJECT_ARRAY, "[[1, 5]]"},
+		{"let h = {\"a\": [1]}; h[\"a\"][-1] = 2; h;", evaluating.OBJECT_HASH, `{"a": [2]}`},
+		{"let f = fn () { [0]; }; let xs = f(); f()[0] = 1; xs;", evaluating.OBJECT_ARRAY, "[0]"},
+		{"let a = [1, 2]; a[0] = a; [a, a];", evaluating.OBJECT_ARRAY, "[[[...], 2], [[...], 2]]"},
+		{"let h = {\"a\": 1}; h[\"b\"] = [h]; h;", evaluating.OBJECT_HASH, "{\"a\": 1, \"b\": [{...}]}"},
+		{"[1, 2, 3, 4][1:3];", evaluating.OBJECT_ARRAY, "[2, 3]"},
+		{"[1, 2, 3][:-1];", evaluating.OBJECT_ARRAY, "[1, 2]"},
+		{"[1, 2, 3][-2:];", evaluating.OBJECT_ARRAY, "[2, 3]"},
+		{"[1, 2, 3][:];", evaluating.OBJECT_ARRAY, "[1, 2, 3]"},
+		{"[1, 2, 3][5:];", evaluating.OBJECT_ARRAY, "[]"},
+		{"[1, 2, 3][2:1];", evaluating.OBJECT_ARRAY, "[]"},
+		{"[1, 2][-10:10];", evaluating.OBJECT_ARRAY, "[1, 2]"},
+		{"let a = [1, 2]; let b = a[:]; b[0] = 9; a;", evaluating.OBJECT_ARRAY, "[1, 2]"},
+		{"\"héllo\"[1:3];", evaluating.OBJECT_STRING, `"él"`},
+		{"\"héllo\"[-2:];", evaluating.OBJECT_STRING, `"lo"`},
+		{"\"abc\"[0];", evaluating.OBJECT_STRING, `"a"`},
+		{"\"héllo\"[1];", evaluating.OBJECT_STRING, `"é"`},
+		{"\"héllo\"[-1];", evaluating.OBJECT_STRING, `"o"`},
+		{"\"abc\"[3];", evaluating.OBJECT_NULL, nil},
+		{"\"abc\"[-4];", evaluating.OBJECT_NULL, nil},
+		{"\"héllo\"[1] == \"é\";", evaluating.OBJECT_BOOLEAN, true},
+		{"\"ab\"[0:1] != \"b\";", evaluating.OBJECT_BOOLEAN, true},
+		{"\"abc\"[0] == \"b\";", evaluating.OBJECT_BOOLEAN, false},
+		{"let word = \"\"; let s = \"日本\"; for (c in s) { word = word + c + s[0]; } word;", evaluating.OBJECT_STRING, `"日日本日"`},
+		{"let c = null; c?.[1:2];", evaluating.OBJECT_NULL, nil},
+		{"1 > 2 ? \"a\" : 2 > 1 ? \"b\" : \"c\";", evaluating.OBJECT_STRING, `"b"`},
+		{"true ? 1 : missing;", evaluating.OBJECT_INTEGER, 1},
+		{"null;", evaluating.OBJECT_NULL, nil},
+		{"[null == null, null != 1, 1 == null, {}[\"a\"] == null];", evaluating.OBJECT_ARRAY, "[true, true, false, true]"},
+		{"let config = {\"db\": {\"host\": \"local\"}}; config?.db?.host;", evaluating.OBJECT_STRING, `"local"`},
+		{"let config = {}; config?.db?.host;", evaluating.OBJECT_NULL, nil},
+		{"let config = null; config?.[missing];", evaluating.OBJECT_NULL, nil},
+		{"[1, 2]?.[1];", evaluating.OBJECT_INTEGER, 2},
+		{"let x = null; x?.[0][1];", evaluating.OBJECT_NULL, nil},
+		{"let config = null; config?.server[\"port\"][1:];", evaluating.OBJECT_NULL, nil},
+		{"let f = null; f?.[0](1)[2];", evaluating.OBJECT_NULL, nil},
+		{"let config = {\"server\": {\"port\": 80}}; config?.server[\"port\"];", evaluating.OBJECT_INTEGER, 80},
+		{"null ?? 0 ?? 1;", evaluating.OBJECT_INTEGER, 0},
+		{"false ?? missing;", evaluating.OBJECT_BOOLEAN, false},
+		{"let config = {}; config?.port ?? 8080;", evaluating.OBJECT_INTEGER, 8080},
+		{"let [a, b] = [1, 2]; a * 10 + b;", evaluating.OBJECT_INTEGER, 12},
+		{"let [first, ...rest] = [1, 2, 3]; rest;", evaluating.OBJECT_ARRAY, "[2, 3]"},
+		{"let [x, ...rest] = [1]; rest;", evaluating.OBJECT_ARRAY, "[]"},
+		{"let pair = fn () { [\"a\", 2] }; let [key, value] = pair(); key;", evaluating.OBJECT_STRING, `"a"`},
+		{"let {name, age: years} = {\"name\": \"Ann\", \"age\": 30}; years;", evaluating.OBJECT_INTEGER, 30},
+		{"let {\"first name\": first} = {\"first name\": \"Ann\", \"extra\": 1}; first;", evaluating.OBJECT_STRING, `"Ann"`},
+		{"let {a} = {\"a\": null}; a;", evaluating.OBJECT_NULL, nil},
+		{"let add = fn (a, b = 2) { a + b; }; [add(1), add(1, 5)];", evaluating.OBJECT_ARRAY, "[3, 6]"},
+		{"let f = fn (a, b = a * 10) { b; }; f(4);", evaluating.OBJECT_INTEGER, 40},
+		{"let f = fn (first, ...rest) { rest; }; f(1, 2, 3);", evaluating.OBJECT_ARRAY, "[2, 3]"},
+		{"let f = fn (a, b = 2, ...r) { r; }; f(1);", evaluating.OBJECT_ARRAY, "[]"},
+		{"fn (a = 1, ...rest) { rest; }();", evaluating.OBJECT_ARRAY, "[]"},
+		{"let f = fn (...all) { all; }; f();", evaluating.OBJECT_ARRAY, "[]"},
+		{"let add = fn (a, b, c) { a + b + c; }; let xs = [2, 3]; add(1, ...xs);", evaluating.OBJECT_INTEGER, 6},
+		{"let xs = [2, 3]; [1, ...xs, ...[], 4];", evaluating.OBJECT_ARRAY, "[1, 2, 3, 4]"},
+		{"let h = {\"a\": 1, \"b\": 2}; {...h, \"b\": 3, \"c\": 4};", evaluating.OBJECT_HASH, `{"a": 1, "b": 3, "c": 4}`},
+		{"{\"a\": 1, \"a\": 2};", evaluating.OBJECT_HASH, `{"a": 2}`},
+		{"fn (a, b = 1, ...c) { a; };", evaluating.OBJECT_FUNCTION, "fn (a, b = 1, ...c)"},
+		{"// comment\nlet a = 2; /* a\ncomment */ a * /**/ 3; // trailing", evaluating.OBJECT_INTEGER, 6},
 	}
 
 	for _, expectation := range expectations {
@@ -94,8 +199,11 @@ func TestBuiltins(t *testing.T) {
 	}{
 		{"len(\"Hello, World!\");", evaluating.OBJECT_INTEGER, 13},
 		{"len(\"\");", evaluating.OBJECT_INTEGER, 0},
+		{"len(\"héllo\");", evaluating.OBJECT_INTEGER, 5},
+		{"let s = \"日本語\"; s[len(s) - 1];", evaluating.OBJECT_STRING, `"語"`},
 		{"len([1, true, fn () { return \"hello\"; }]);", evaluating.OBJECT_INTEGER, 3},
 		{"len(2);", evaluating.OBJECT_ERROR, "Type builtin function \"len\" expects a string or array, got integer."},
+		{"len(1, 2);", evaluating.OBJECT_ERROR, "Wrong number of arguments for len(value). Expected 1, got 2."},
 	}
 
 	for _, expectation := range expectations {
@@ -161,10 +269,92 @@ func TestEvalError(t *testing.T) {
 		{"true + 2;", "Type mismatch: boolean + integer."},
 		{"2 * false;", "Type mismatch: integer * boolean."},
 		{"a;", "Identifier not found: \"a\"."},
+		{`"value: ${missing}";`, "Identifier not found: \"missing\"."},
+		{"true && missing;", "Identifier not found: \"missing\"."},
+		{"null ?? missing;", "Identifier not found: \"missing\"."},
+		{"let a = 5; a?.b;", "Expression \"a\" is not a indexable."},
+		{"let x = null; let n = x?.[0]; n[1];", "Expression \"n\" is not a indexable."},
+		{"let x = {\"a\": null}; x?.a[0];", "Expression \"x?.a\" is not a indexable."},
+		{"missing ? 1 : 2;", "Identifier not found: \"missing\"."},
+		{"missing + 1;", "Identifier not found: \"missing\"."},
+		{"1 + missing;", "Identifier not found: \"missing\"."},
+		{"-missing;", "Identifier not found: \"missing\"."},
+		{"!missing;", "Identifier not found: \"missing\"."},
+		{"if (missing) { 1; } else { 2; };", "Identifier not found: \"missing\"."},
+		{"[1, missing];", "Identifier not found: \"missing\"."},
+		{"let f = fn (a) { a; }; f(missing);", "Identifier not found: \"missing\"."},
+		{"missing(1);", "Identifier not found: \"missing\"."},
+		{"{missing: 1};", "Identifier not found: \"missing\"."},
+		{"{\"a\": missing};", "Identifier not found: \"missing\"."},
+		{"missing[0];", "Identifier not found: \"missing\"."},
+		{"[1][missing];", "Identifier not found: \"missing\"."},
+		{"let a = missing; a;", "Identifier not found: \"missing\"."},
+		{"let a = 1; a = missing;", "Identifier not found: \"missing\"."},
+		{"let a = 1; a = 1 % 0; a;", "Division by zero: 1 % 0."},
+		{"let h = {}; h[missing] = 1;", "Identifier not found: \"missing\"."},
+		{"let h = {}; h[\"a\"] = missing;", "Identifier not found: \"missing\"."},
+		{"let f = fn () { return missing; }; f();", "Identifier not found: \"missing\"."},
+		{"false ? 1 : 1 + true;", "Type mismatch: integer + boolean."},
+		{"5 % 0;", "Division by zero: 5 % 0."},
+		{"5 / 0;", "Division by zero: 5 / 0."},
+		{"let [a, b] = [1];", "Wrong number of items to destructure. Expected 2, got 1."},
+		{"let [a] = [1, 2];", "Wrong number of items to destructure. Expected 1, got 2."},
+		{"let [a, b, ...c] = [1];", "Wrong number of items to destructure. Expected at least 2, got 1."},
+		{"let [a] = {};", "Expected array to destructure, got hash."},
+		{"let {a} = [1];", "Expected hash to destructure, got array."},
+		{"let {name} = {\"age\": 1};", "Missing key \"name\" to destructure."},
+		{"let a = 1; let [a] = [2];", "Identifier already declared in this scope: \"a\"."},
+		{"let [a, a] = [1, 2];", "Identifier already declared in this scope: \"a\"."},
+		{"let [a] = missing;", "Identifier not found: \"missing\"."},
+		{"[1][true];", "Unsupported array index, must be of type integer, got type boolean."},
+		{"let f = fn (n) { f(n + 1); }; f(0);", "Maximum call depth of 1000 exceeded."},
+		{"2 ** -1;", "Negative exponent: 2 ** -1."},
+		{"2 ** 63;", "Integer overflow: 2 ** 63."},
+		{"3 ** 40;", "Integer overflow: 3 ** 40."},
+		{"1 << -1;", "Negative shift count: 1 << -1."},
+		{"1 >> -1;", "Negative shift count: 1 >> -1."},
+		{"1 << 63;", "Integer overflow: 1 << 63."},
+		{"3 << 64;", "Integer overflow: 3 << 64."},
+		{"9223372036854775807 + 1;", "Integer overflow: 9223372036854775807 + 1."},
+		{"-9223372036854775807 - 2;", "Integer overflow: -9223372036854775807 - 2."},
+		{"4611686018427387904 * 2;", "Integer overflow: 4611686018427387904 * 2."},
+		{"(-2) ** 63 / -1;", "Integer overflow: -9223372036854775808 / -1."},
+		{"-((-2) ** 63);", "Integer overflow: -(-9223372036854775808)."},
+		{"~true;", "Type mismatch: ~boolean."},
+		{"for (x in 5) { x; }", "Expression \"5\" is not iterable."},
+		{"while (missing) { 1; }", "Identifier not found: \"missing\"."},
+		{"for (x in [1, 2]) { x + true; }", "Type mismatch: integer + boolean."},
+		{"true & false;", "Type mismatch: boolean & boolean."},
 		{"let a = 2; let a = 3;", "Identifier already declared in this scope: \"a\"."},
-		{"let a = 2; fn (a) { a; };", "Identifier already declared in this scope: \"a\"."},
-		{"fn (a) { return a; }(2, 3);", "Wrong number of arguments. Expected 1, got 2."},
-		{"fn (a) { return a; }();", "Wrong number of arguments. Expected 1, got 0."},
+		{"const a = 1; a = 2;", "Cannot assign to constant: \"a\"."},
+		{"const {b} = {\"b\": 2}; b = 3;", "Cannot assign to constant: \"b\"."},
+		{"let f = fn () { limit = 2; }; const limit = 1; f();", "Cannot assign to constant: \"limit\"."},
+		{"const a = 1; const a = 2;", "Identifier already declared in this scope: \"a\"."},
+		{"fn (a, a) { a; };", "Identifier already declared in this scope: \"a\"."},
+		{"b = 1;", "Identifier not found: \"b\"."},
+		{"let a = [1]; a[1] = 2;", "Index 1 out of range for array of length 1."},
+		{"let a = [1]; a[-2] = 2;", "Index -2 out of range for array of length 1."},
+		{"let a = [[1]]; a[0][3] = 2;", "Index 3 out of range for array of length 1."},
+		{"let a = [1]; a[0][0] = 2;", "Expression \"a[0][0]\" is not assignable."},
+		{"let a = [1]; a[\"x\"] = 2;", "Unsupported array index, must be of type integer, got type string."},
+		{"let h = {}; h[[1]] = 2;", "Unsupported index, must be of type integer, string, or boolean, got type array."},
+		{"let s = \"abc\"; s[0] = \"x\";", "Expression \"s[0]\" is not assignable."},
+		{"1 = 2;", "Expression \"1\" is not assignable."},
+		{"5[1:2];", "Expression \"5\" is not sliceable."},
+		{"\"abc\"[true];", "Unsupported string index, must be of type integer, got type boolean."},
+		{"\"abc\"?.length;", "Unsupported string index, must be of type integer, got type string."},
+		{"[1][true:];", "Unsupported slice index, must be of type integer, got type boolean."},
+		{"[1][:missing];", "Identifier not found: \"missing\"."},
+		{"fn (a) { return a; }(2, 3);", "Wrong number of arguments for fn (a). Expected 1, got 2."},
+		{"fn (a) { return a; }();", "Wrong number of arguments for fn (a). Expected 1, got 0."},
+		{"let f = fn (a, b = 2) { a; }; f();", "Wrong number of arguments for f(a, b = 2). Expected 1 to 2, got 0."},
+		{"let f = fn (a, b = 2) { a; }; f(1, 2, 3);", "Wrong number of arguments for f(a, b = 2). Expected 1 to 2, got 3."},
+		{"let f = fn (a, ...rest) { a; }; f();", "Wrong number of arguments for f(a, ...rest). Expected at least 1, got 0."},
+		{"let f = fn (a = missing) { a; }; f();", "Identifier not found: \"missing\"."},
+		{"let f = fn (a) { a; }; f(...1);", "Expected array to spread, got integer."},
+		{"[...{}];", "Expected array to spread, got hash."},
+		{"{...[1]};", "Expected hash to spread, got array."},
+		{"[...missing];", "Identifier not found: \"missing\"."},
 	}
 
 	for _, expectation := range expectations {
@@ -193,3 +383,182 @@ func TestEvalError(t *testing.T) {
 		}
 	}
 }
+
+func TestEvalOverflowPolicy(t *testing.T) {
+	expectations := []struct {
+		policy     evaluating.OverflowPolicy
+		input      string
+		objectType evaluating.ObjectType
+		output     string
+	}{
+		{evaluating.OVERFLOW_WRAP, "9223372036854775807 + 1;", evaluating.OBJECT_INTEGER, "-9223372036854775808"},
+		{evaluating.OVERFLOW_WRAP, "-9223372036854775807 - 2;", evaluating.OBJECT_INTEGER, "9223372036854775807"},
+		{evaluating.OVERFLOW_WRAP, "4611686018427387904 * 2;", evaluating.OBJECT_INTEGER, "-9223372036854775808"},
+		{evaluating.OVERFLOW_WRAP, "3 ** 41;", evaluating.OBJECT_INTEGER, "-420491770248316829"},
+		{evaluating.OVERFLOW_WRAP, "2 ** 64;", evaluating.OBJECT_INTEGER, "0"},
+		{evaluating.OVERFLOW_WRAP, "3 << 63;", evaluating.OBJECT_INTEGER, "-9223372036854775808"},
+		{evaluating.OVERFLOW_WRAP, "(-2) ** 63 / -1;", evaluating.OBJECT_INTEGER, "-9223372036854775808"},
+		{evaluating.OVERFLOW_WRAP, "-((-2) ** 63);", evaluating.OBJECT_INTEGER, "-9223372036854775808"},
+		{evaluating.OVERFLOW_PROMOTE, "9223372036854775807 + 1;", evaluating.OBJECT_BIG_INTEGER, "9223372036854775808"},
+		{evaluating.OVERFLOW_PROMOTE, "2 ** 100;", evaluating.OBJECT_BIG_INTEGER, "1267650600228229401496703205376"},
+		{evaluating.OVERFLOW_PROMOTE, "-(2 ** 64);", evaluating.OBJECT_BIG_INTEGER, "-18446744073709551616"},
+		{evaluating.OVERFLOW_PROMOTE, "-((-2) ** 63);", evaluating.OBJECT_BIG_INTEGER, "9223372036854775808"},
+		{evaluating.OVERFLOW_PROMOTE, "(-2) ** 63 / -1;", evaluating.OBJECT_BIG_INTEGER, "9223372036854775808"},
+		{evaluating.OVERFLOW_PROMOTE, "2 ** 64 / 2 ** 62;", evaluating.OBJECT_INTEGER, "4"},
+		{evaluating.OVERFLOW_PROMOTE, "2 ** 64 - 2 ** 64 + 1;", evaluating.OBJECT_INTEGER, "1"},
+		{evaluating.OVERFLOW_PROMOTE, "2 ** 64 % 10;", evaluating.OBJECT_INTEGER, "6"},
+		{evaluating.OVERFLOW_PROMOTE, "(1 << 80) >> 79;", evaluating.OBJECT_INTEGER, "2"},
+		{evaluating.OVERFLOW_PROMOTE, "-(1 << 80) >> 1000;", evaluating.OBJECT_INTEGER, "-1"},
+		{evaluating.OVERFLOW_PROMOTE, "~(1 << 80) & 3;", evaluating.OBJECT_INTEGER, "3"},
+		{evaluating.OVERFLOW_PROMOTE, "2 ** 64 == 2 ** 64;", evaluating.OBJECT_BOOLEAN, "true"},
+		{evaluating.OVERFLOW_PROMOTE, "2 ** 64 == 1;", evaluating.OBJECT_BOOLEAN, "false"},
+		{evaluating.OVERFLOW_PROMOTE, "2 ** 64 > 9223372036854775807;", evaluating.OBJECT_BOOLEAN, "true"},
+		{evaluating.OVERFLOW_PROMOTE, "let h = {2 ** 70: \"big\"}; h[2 ** 70];", evaluating.OBJECT_STRING, `"big"`},
+		{evaluating.OVERFLOW_PROMOTE, "[1][2 ** 70];", evaluating.OBJECT_NULL, "null"},
+		{evaluating.OVERFLOW_PROMOTE, "\"abc\"[-(2 ** 70)];", evaluating.OBJECT_NULL, "null"},
+		{evaluating.OVERFLOW_PROMOTE, "[1, 2][-(2 ** 70):2 ** 70];", evaluating.OBJECT_ARRAY, "[1, 2]"},
+		{evaluating.OVERFLOW_PROMOTE, "let a = [1]; a[2 ** 70] = 2;", evaluating.OBJECT_ERROR, "Index 1180591620717411303424 out of range for array of length 1."},
+		{evaluating.OVERFLOW_PROMOTE, "\"${2 ** 64}\";", evaluating.OBJECT_STRING, `"18446744073709551616"`},
+		{evaluating.OVERFLOW_PROMOTE, "2 ** 200;", evaluating.OBJECT_ERROR, "Integer overflow: 2 ** 200."},
+		{evaluating.OVERFLOW_PROMOTE, "1 << 128;", evaluating.OBJECT_ERROR, "Integer overflow: 1 << 128."},
+		{evaluating.OVERFLOW_PROMOTE, "let x = 2 ** 100; x * x;", evaluating.OBJECT_ERROR, "Integer overflow: 1267650600228229401496703205376 * 1267650600228229401496703205376."},
+		{evaluating.OVERFLOW_PROMOTE, "2 ** 64 / 0;", evaluating.OBJECT_ERROR, "Division by zero: 18446744073709551616 / 0."},
+		{evaluating.OVERFLOW_PROMOTE, "2 ** 64 + true;", evaluating.OBJECT_ERROR, "Type mismatch: integer + boolean."},
+	}
+
+	for _, expectation := range expectations {
+		lexer := lexing.NewLexer(expectation.input)
+		parser := parsing.NewParser(lexer)
+		ast := parser.Parse()
+		environment := evaluating.NewEnvironment(nil)
+		environment.Runtime.Overflow = expectation.policy
+		environment.Runtime.MaxIntegerBits = 128
+		object := evaluating.Eval(environment, ast)
+
+		if object.Type() != expectation.objectType {
+			t.Fatalf(
+				"Expected %q to be of type %s, got %s (%s).",
+				expectation.input,
+				evaluating.ObjectTypeToString(expectation.objectType),
+				evaluating.ObjectTypeToString(object.Type()),
+				object.Inspect(),
+			)
+		}
+		if object.Inspect() != expectation.output {
+			t.Fatalf("Expected %q, got %q.", expectation.output, object.Inspect())
+		}
+	}
+}
+
+func TestEvalStackTrace(t *testing.T) {
+	content := `let inner = fn (x) { x + missing; };
+let outer = fn (x) {
+  let helper = fn () { inner(x); };
+  helper();
+};
+outer(1);`
+
+	lexer := lexing.NewLexer(content)
+	parser := parsing.NewParser(lexer)
+	ast := parser.Parse()
+	environment := evaluating.NewEnvironment(nil)
+	object := evaluating.Eval(environment, ast)
+
+	error, ok := object.(*evaluating.ObjectError)
+	if !ok {
+		t.Fatalf("Expected an error, got %s.", object.Inspect())
+	}
+
+	names := []string{}
+	for _, frame := range error.Trace {
+		names = append(names, frame.Name)
+	}
+	if strings.Join(names, " ") != "outer helper inner" {
+		t.Fatalf("Expected frames outer helper inner, got %v.", names)
+	}
+	if error.Span.Start.String() != "1:26" {
+		t.Fatalf("Expected error at 1:26, got %s.", error.Span.Start.String())
+	}
+
+	expected := `Traceback (most recent call last):
+  at 6:1 in <main>
+  at 4:3 in outer
+  at 3:24 in helper
+  at 1:26 in inner
+Error: Identifier not found: "missing".`
+	if error.StackTrace() != expected {
+		t.Fatalf("Expected:\n%s\nGot:\n%s", expected, error.StackTrace())
+	}
+}
+
+func TestEvalStackTraceRecursion(t *testing.T) {
+	lexer := lexing.NewLexer("let f = fn (n) { if (n == 0) { missing; } else { f(n - 1); } };\nf(10);")
+	parser := parsing.NewParser(lexer)
+	ast := parser.Parse()
+	environment := evaluating.NewEnvironment(nil)
+	object := evaluating.Eval(environment, ast)
+
+	expected := `Traceback (most recent call last):
+  at 2:1 in <main>
+  at 1:50 in f
+  [previous line repeated 9 more times]
+  at 1:32 in f
+Error: Identifier not found: "missing".`
+	if stackTrace := object.(*evaluating.ObjectError).StackTrace(); stackTrace != expected {
+		t.Fatalf("Expected:\n%s\nGot:\n%s", expected, stackTrace)
+	}
+}
+
+func TestEvalStepLimit(t *testing.T) {
+	expectations := []struct {
+		input  string
+		steps  int
+		output string
+	}{
+		{"let i = 0; while (true) { i = i + 1; };", 100, "Maximum of 100 steps exceeded."},
+		{"for (x in [1, 2, 3]) { x; };", 2, "Maximum of 2 steps exceeded."},
+		{"let f = fn (n) { f(n + 1); }; f(0);", 50, "Maximum of 50 steps exceeded."},
+	}
+
+	for _, expectation := range expectations {
+		lexer := lexing.NewLexer(expectation.input)
+		parser := parsing.NewParser(lexer)
+		ast := parser.Parse()
+		environment := evaluating.NewEnvironment(nil)
+		environment.Runtime.MaxSteps = expectation.steps
+		object := evaluating.Eval(environment, ast)
+
+		error, ok := object.(*evaluating.ObjectError)
+		if !ok {
+			t.Fatalf("Expected an error for %q, got %s.", expectation.input, object.Inspect())
+		}
+		if error.Message != expectation.output {
+			t.Fatalf("Expected %q, got %q.", expectation.output, error.Message)
+		}
+	}
+}
+
+func FuzzEval(f *testing.F) {
+	f.Add("let a = [1, 2]; let b = {\"a\": fn (x) { return x * 2; }}; b[\"a\"](a[0]);")
+	f.Add("let f = fn (n) { if (n < 2) { n; } else { f(n - 1) + f(n - 2); } }; f(5);")
+	f.Add("let c = {}; [c?.a?.[1] ?? 2, 1 > 2 ? \"a\" : \"b\", 7 % 0, 2 ** 70, ~1 << 3];")
+	f.Add("let a = [1, 2, 3]; a[-1] = a[:2]; [a[-1][1:], \"héllo\"[1:-1]];")
+	f.Add("let f = fn (a, b = 2, ...r) { [a, b, r]; }; f(1); fn (a = 1, ...rest) { rest; }();")
+	f.Add("let i = 0; while (true) { i = i + 1; }; for (x in [1, 2]) { if (x > 1) { break; } };")
+	f.Add("let x = ); +; fn (1) {}; } ] ) let = ; return")
+
+	f.Fuzz(func(t *testing.T, content string) {
+		lexer := lexing.NewLexer(content)
+		parser := parsing.NewParser(lexer)
+		ast := parser.Parse()
+		environment := evaluating.NewEnvironment(nil)
+		environment.Runtime.MaxCallDepth = 16
+		environment.Runtime.MaxSteps = 16
+
+		object := evaluating.Eval(environment, ast)
+		if object == nil {
+			t.Fatalf("Evaluating %q returned nil.", content)
+		}
+		_ = object.Inspect()
+	})
+}
@@ -1,8 +1,13 @@
 package lexing_test
 
 import (
+	"errors"
+	"io"
 	"monkey/lexing"
+	"slices"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestLexer(t *testing.T) {
@@ -164,3 +169,572 @@ e[4] == 5;
 		}
 	}
 }
+
+func asciiPosition(line int, column int, offset int) lexing.Position {
+	return lexing.Position{
+		Line:       line,
+		Column:     column,
+		ByteColumn: column,
+		Offset:     offset,
+		RuneOffset: offset,
+	}
+}
+
+func TestLexerPositions(t *testing.T) {
+	content := "let a = 1;\n  a + 22;"
+
+	expectations := []struct {
+		literal string
+		start   lexing.Position
+		end     lexing.Position
+	}{
+		{"let", asciiPosition(1, 1, 0), asciiPosition(1, 4, 3)},
+		{"a", asciiPosition(1, 5, 4), asciiPosition(1, 6, 5)},
+		{"=", asciiPosition(1, 7, 6), asciiPosition(1, 8, 7)},
+		{"1", asciiPosition(1, 9, 8), asciiPosition(1, 10, 9)},
+		{";", asciiPosition(1, 10, 9), asciiPosition(1, 11, 10)},
+		{"a", asciiPosition(2, 3, 13), asciiPosition(2, 4, 14)},
+		{"+", asciiPosition(2, 5, 15), asciiPosition(2, 6, 16)},
+		{"22", asciiPosition(2, 7, 17), asciiPosition(2, 9, 19)},
+		{";", asciiPosition(2, 9, 19), asciiPosition(2, 10, 20)},
+		{"\x00", asciiPosition(2, 10, 20), asciiPosition(2, 10, 20)},
+	}
+
+	lexer := lexing.NewLexer(content)
+
+	for index, expectation := range expectations {
+		token := lexer.Next()
+
+		if token.Literal != expectation.literal ||
+			token.Span.Start != expectation.start ||
+			token.Span.End != expectation.end {
+			t.Fatalf(
+				"[%d] Expected token %q at %v-%v, found token %q at %v-%v.",
+				index,
+				expectation.literal,
+				expectation.start,
+				expectation.end,
+				token.Literal,
+				token.Span.Start,
+				token.Span.End,
+			)
+		}
+	}
+}
+
+func TestLexerStrings(t *testing.T) {
+	expectations := []struct {
+		input     string
+		tokenType lexing.TokenType
+		value     string
+		message   string
+	}{
+		{`"plain"`, lexing.TOKEN_STRING, "plain", ""},
+		{`"a\nb\tc\rd"`, lexing.TOKEN_STRING, "a\nb\tc\rd", ""},
+		{`"say \"hi\" \\ bye"`, lexing.TOKEN_STRING, `say "hi" \ bye`, ""},
+		{`"nul\0"`, lexing.TOKEN_STRING, "nul\x00", ""},
+		{`"\u{48}\u{e9}\u{1F600}"`, lexing.TOKEN_STRING, "Hé😀", ""},
+		{"`raw \\n \"string\"\nsecond line`", lexing.TOKEN_STRING, "raw \\n \"string\"\nsecond line", ""},
+		{`"bad \q"`, lexing.TOKEN_ILLEGAL, `"bad \q"`, `Invalid escape sequence "\q".`},
+		{`"\u48"`, lexing.TOKEN_ILLEGAL, `"\u48"`, `Invalid unicode escape, expected "{" after "\u".`},
+		{`"\u{48"`, lexing.TOKEN_ILLEGAL, `"\u{48"`, `Invalid unicode escape, expected "}" after the hexadecimal digits.`},
+		{`"\u{}"`, lexing.TOKEN_ILLEGAL, `"\u{}"`, `Invalid unicode escape "\u{}", expected 1 to 6 hexadecimal digits.`},
+		{`"\u{D800}"`, lexing.TOKEN_ILLEGAL, `"\u{D800}"`, `Invalid unicode escape "\u{D800}", not a valid code point.`},
+	}
+
+	for _, expectation := range expectations {
+		lexer := lexing.NewLexer(expectation.input)
+		token := lexer.Next()
+
+		if token.Type != expectation.tokenType ||
+			token.Literal != expectation.value ||
+			token.Message != expectation.message {
+			t.Fatalf(
+				"Expected token %q of type %d with message %q, found token %q of type %d with message %q.",
+				expectation.value,
+				expectation.tokenType,
+				expectation.message,
+				token.Literal,
+				token.Type,
+				token.Message,
+			)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	expectations := []struct {
+		value  string
+		quoted string
+	}{
+		{"Hello, World", `"Hello, World"`},
+		{"a\nb\tc\rd\x00", `"a\nb\tc\rd\0"`},
+		{`say "hi" \ bye`, `"say \"hi\" \\ bye"`},
+		{"Hé😀", `"Hé😀"`},
+		{"\x07\u200b", `"\u{7}\u{200B}"`},
+		{"cost: ${price}", `"cost: \${price}"`},
+	}
+
+	for _, expectation := range expectations {
+		quoted := lexing.Quote(expectation.value)
+		if quoted != expectation.quoted {
+			t.Fatalf("Expected %s, got %s.", expectation.quoted, quoted)
+		}
+
+		lexer := lexing.NewLexer(quoted)
+		token := lexer.Next()
+		if token.Type != lexing.TOKEN_STRING || token.Literal != expectation.value {
+			t.Fatalf("Expected %s to lex back into %q, got %q.", quoted, expectation.value, token.Literal)
+		}
+	}
+}
+
+func TestLexerIllegal(t *testing.T) {
+	expectations := []struct {
+		input   string
+		value   string
+		message string
+	}{
+		{`"never closed`, `"never closed`, "Unterminated string literal."},
+		{`"escaped at end\`, `"escaped at end\`, "Unterminated string literal."},
+		{"`never closed", "`never closed", "Unterminated raw string literal."},
+		{"@", "@", `Unexpected character "@".`},
+		{"\x00", "\x00", `Unexpected character "\x00".`},
+	}
+
+	for _, expectation := range expectations {
+		lexer := lexing.NewLexer(expectation.input)
+		token := lexer.Next()
+
+		if token.Type != lexing.TOKEN_ILLEGAL ||
+			token.Literal != expectation.value ||
+			token.Message != expectation.message {
+			t.Fatalf(
+				"Expected illegal token %q with message %q, found token %q of type %d with message %q.",
+				expectation.value,
+				expectation.message,
+				token.Literal,
+				token.Type,
+				token.Message,
+			)
+		}
+
+		token = lexer.Next()
+		if token.Type != lexing.TOKEN_EOF {
+			t.Fatalf("Expected eof after %q, found token %q.", expectation.input, token.Literal)
+		}
+	}
+}
+
+func TestLexerEmpty(t *testing.T) {
+	for _, input := range []string{"", "  \n\t"} {
+		lexer := lexing.NewLexer(input)
+
+		for range 2 {
+			token := lexer.Next()
+			if token.Type != lexing.TOKEN_EOF {
+				t.Fatalf("Expected eof for %q, found token %q.", input, token.Literal)
+			}
+		}
+	}
+}
+
+func TestLexerComments(t *testing.T) {
+	content := `// leading comment
+let a = 1; // trailing comment
+/* block
+   comment */ a /* inline */ / 2;
+// last comment`
+
+	expectations := []struct {
+		tokenType lexing.TokenType
+		value     string
+		leading   []lexing.TriviaType
+		trailing  []lexing.TriviaType
+	}{
+		{lexing.TOKEN_LET, "let", []lexing.TriviaType{lexing.TRIVIA_LINE_COMMENT, lexing.TRIVIA_WHITESPACE}, []lexing.TriviaType{lexing.TRIVIA_WHITESPACE}},
+		{lexing.TOKEN_IDENTIFIER, "a", []lexing.TriviaType{}, []lexing.TriviaType{lexing.TRIVIA_WHITESPACE}},
+		{lexing.TOKEN_ASSIGN, "=", []lexing.TriviaType{}, []lexing.TriviaType{lexing.TRIVIA_WHITESPACE}},
+		{lexing.TOKEN_INTEGER, "1", []lexing.TriviaType{}, []lexing.TriviaType{}},
+		{lexing.TOKEN_SEMICOLON, ";", []lexing.TriviaType{}, []lexing.TriviaType{lexing.TRIVIA_WHITESPACE, lexing.TRIVIA_LINE_COMMENT}},
+		{lexing.TOKEN_IDENTIFIER, "a", []lexing.TriviaType{lexing.TRIVIA_WHITESPACE, lexing.TRIVIA_BLOCK_COMMENT, lexing.TRIVIA_WHITESPACE}, []lexing.TriviaType{lexing.TRIVIA_WHITESPACE}},
+		{lexing.TOKEN_SLASH, "/", []lexing.TriviaType{lexing.TRIVIA_BLOCK_COMMENT, lexing.TRIVIA_WHITESPACE}, []lexing.TriviaType{lexing.TRIVIA_WHITESPACE}},
+		{lexing.TOKEN_INTEGER, "2", []lexing.TriviaType{}, []lexing.TriviaType{}},
+		{lexing.TOKEN_SEMICOLON, ";", []lexing.TriviaType{}, []lexing.TriviaType{}},
+		{lexing.TOKEN_EOF, "\x00", []lexing.TriviaType{lexing.TRIVIA_WHITESPACE, lexing.TRIVIA_LINE_COMMENT}, []lexing.TriviaType{}},
+	}
+
+	lexer := lexing.NewLexer(content)
+	reconstructed := ""
+
+	for index, expectation := range expectations {
+		token := lexer.Next()
+
+		if token.Type != expectation.tokenType ||
+			token.Literal != expectation.value {
+			t.Fatalf(
+				"[%d] Expected token %q of type %d, found token %q of type %d.",
+				index,
+				expectation.value,
+				expectation.tokenType,
+				token.Literal,
+				token.Type,
+			)
+		}
+
+		triviaTypes := func(trivia []*lexing.Trivia) []lexing.TriviaType {
+			types := []lexing.TriviaType{}
+			for _, item := range trivia {
+				types = append(types, item.Type)
+			}
+			return types
+		}
+		if !slices.Equal(triviaTypes(token.LeadingTrivia), expectation.leading) ||
+			!slices.Equal(triviaTypes(token.TrailingTrivia), expectation.trailing) {
+			t.Fatalf(
+				"[%d] Expected trivia %v and %v, found %v and %v.",
+				index,
+				expectation.leading,
+				expectation.trailing,
+				triviaTypes(token.LeadingTrivia),
+				triviaTypes(token.TrailingTrivia),
+			)
+		}
+
+		for _, trivia := range token.LeadingTrivia {
+			reconstructed += trivia.Text
+		}
+		reconstructed += content[token.Span.Start.Offset:token.Span.End.Offset]
+		for _, trivia := range token.TrailingTrivia {
+			reconstructed += trivia.Text
+		}
+	}
+
+	if reconstructed != content {
+		t.Fatalf("Expected trivia to reproduce %q, got %q.", content, reconstructed)
+	}
+}
+
+func TestLexerUnterminatedComment(t *testing.T) {
+	lexer := lexing.NewLexer("1 /* never closed")
+
+	lexer.Next()
+	token := lexer.Next()
+
+	if token.Type != lexing.TOKEN_ILLEGAL ||
+		token.Literal != "/* never closed" ||
+		token.Message != "Unterminated block comment." {
+		t.Fatalf(
+			"Expected unterminated block comment, found token %q of type %d with message %q.",
+			token.Literal,
+			token.Type,
+			token.Message,
+		)
+	}
+}
+
+func TestLexerUnicode(t *testing.T) {
+	content := "let café = \"ü\";\nπ + 日本語_2;\n€"
+
+	expectations := []struct {
+		tokenType lexing.TokenType
+		value     string
+		start     lexing.Position
+	}{
+		{lexing.TOKEN_LET, "let", lexing.Position{Line: 1, Column: 1, ByteColumn: 1, Offset: 0, RuneOffset: 0}},
+		{lexing.TOKEN_IDENTIFIER, "café", lexing.Position{Line: 1, Column: 5, ByteColumn: 5, Offset: 4, RuneOffset: 4}},
+		{lexing.TOKEN_ASSIGN, "=", lexing.Position{Line: 1, Column: 10, ByteColumn: 11, Offset: 10, RuneOffset: 9}},
+		{lexing.TOKEN_STRING, "ü", lexing.Position{Line: 1, Column: 12, ByteColumn: 13, Offset: 12, RuneOffset: 11}},
+		{lexing.TOKEN_SEMICOLON, ";", lexing.Position{Line: 1, Column: 15, ByteColumn: 17, Offset: 16, RuneOffset: 14}},
+		{lexing.TOKEN_IDENTIFIER, "π", lexing.Position{Line: 2, Column: 1, ByteColumn: 1, Offset: 18, RuneOffset: 16}},
+		{lexing.TOKEN_PLUS, "+", lexing.Position{Line: 2, Column: 3, ByteColumn: 4, Offset: 21, RuneOffset: 18}},
+		{lexing.TOKEN_IDENTIFIER, "日本語_2", lexing.Position{Line: 2, Column: 5, ByteColumn: 6, Offset: 23, RuneOffset: 20}},
+		{lexing.TOKEN_SEMICOLON, ";", lexing.Position{Line: 2, Column: 10, ByteColumn: 17, Offset: 34, RuneOffset: 25}},
+		{lexing.TOKEN_ILLEGAL, "€", lexing.Position{Line: 3, Column: 1, ByteColumn: 1, Offset: 36, RuneOffset: 27}},
+		{lexing.TOKEN_EOF, "\x00", lexing.Position{Line: 3, Column: 2, ByteColumn: 4, Offset: 39, RuneOffset: 28}},
+	}
+
+	lexer := lexing.NewLexer(content)
+
+	for index, expectation := range expectations {
+		token := lexer.Next()
+
+		if token.Type != expectation.tokenType ||
+			token.Literal != expectation.value ||
+			token.Span.Start != expectation.start {
+			t.Fatalf(
+				"[%d] Expected token %q of type %d at %+v, found token %q of type %d at %+v.",
+				index,
+				expectation.value,
+				expectation.tokenType,
+				expectation.start,
+				token.Literal,
+				token.Type,
+				token.Span.Start,
+			)
+		}
+	}
+
+	lexer = lexing.NewLexer("a\xffb")
+	lexer.Next()
+	token := lexer.Next()
+	if token.Type != lexing.TOKEN_ILLEGAL || token.Message != "Invalid UTF-8 encoding." {
+		t.Fatalf("Expected invalid encoding, found token %q with message %q.", token.Literal, token.Message)
+	}
+}
+
+func TestLexerReader(t *testing.T) {
+	content := "let naïve = `multi\nline`; /* comment */ naïve;"
+	reader := iotest.OneByteReader(strings.NewReader(content))
+	lexer := lexing.NewReaderLexer("stream.mk", reader)
+
+	expectations := []struct {
+		tokenType lexing.TokenType
+		value     string
+		start     string
+	}{
+		{lexing.TOKEN_LET, "let", "stream.mk:1:1"},
+		{lexing.TOKEN_IDENTIFIER, "naïve", "stream.mk:1:5"},
+		{lexing.TOKEN_ASSIGN, "=", "stream.mk:1:11"},
+		{lexing.TOKEN_STRING, "multi\nline", "stream.mk:1:13"},
+		{lexing.TOKEN_SEMICOLON, ";", "stream.mk:2:6"},
+		{lexing.TOKEN_IDENTIFIER, "naïve", "stream.mk:2:22"},
+		{lexing.TOKEN_SEMICOLON, ";", "stream.mk:2:27"},
+		{lexing.TOKEN_EOF, "\x00", "stream.mk:2:28"},
+	}
+
+	for index, expectation := range expectations {
+		token := lexer.Next()
+
+		if token.Type != expectation.tokenType ||
+			token.Literal != expectation.value ||
+			token.Span.Start.String() != expectation.start {
+			t.Fatalf(
+				"[%d] Expected token %q of type %d at %s, found token %q of type %d at %s.",
+				index,
+				expectation.value,
+				expectation.tokenType,
+				expectation.start,
+				token.Literal,
+				token.Type,
+				token.Span.Start,
+			)
+		}
+	}
+}
+
+func TestLexerReaderError(t *testing.T) {
+	reader := io.MultiReader(
+		strings.NewReader("let a"),
+		iotest.ErrReader(errors.New("connection reset")),
+	)
+	lexer := lexing.NewReaderLexer("", reader)
+
+	lexer.Next()
+	lexer.Next()
+	token := lexer.Next()
+
+	if token.Type != lexing.TOKEN_ILLEGAL ||
+		token.Message != "Failed to read source: connection reset." {
+		t.Fatalf(
+			"Expected read failure, found token %q of type %d with message %q.",
+			token.Literal,
+			token.Type,
+			token.Message,
+		)
+	}
+
+	token = lexer.Next()
+	if token.Type != lexing.TOKEN_EOF {
+		t.Fatalf("Expected eof after read failure, found token %q.", token.Literal)
+	}
+}
+
+func TestLexerIntegers(t *testing.T) {
+	expectations := []struct {
+		input     string
+		tokenType lexing.TokenType
+		value     string
+		message   string
+	}{
+		{"1234567890", lexing.TOKEN_INTEGER, "1234567890", ""},
+		{"1_000_000", lexing.TOKEN_INTEGER, "1_000_000", ""},
+		{"0xFF_ff", lexing.TOKEN_INTEGER, "0xFF_ff", ""},
+		{"0o755", lexing.TOKEN_INTEGER, "0o755", ""},
+		{"0b1010_0101", lexing.TOKEN_INTEGER, "0b1010_0101", ""},
+		{"007", lexing.TOKEN_INTEGER, "007", ""},
+		{"1e6", lexing.TOKEN_INTEGER, "1e6", ""},
+		{"2.5E+3", lexing.TOKEN_INTEGER, "2.5E+3", ""},
+		{"1_000.000_1e-4", lexing.TOKEN_INTEGER, "1_000.000_1e-4", ""},
+		{"0x1e5", lexing.TOKEN_INTEGER, "0x1e5", ""},
+		{"1..2", lexing.TOKEN_INTEGER, "1", ""},
+		{"1e", lexing.TOKEN_ILLEGAL, "1e", "The exponent of the decimal literal has no digits."},
+		{"1e+", lexing.TOKEN_ILLEGAL, "1e+", "The exponent of the decimal literal has no digits."},
+		{"1e_5", lexing.TOKEN_ILLEGAL, "1e_5", "Underscores in integer literals must separate digits."},
+		{"1._5", lexing.TOKEN_INTEGER, "1", ""},
+		{"1.5f", lexing.TOKEN_ILLEGAL, "1.5f", "Invalid character 'f' in integer literal."},
+		{"1__0", lexing.TOKEN_ILLEGAL, "1__0", "Underscores in integer literals must separate digits."},
+		{"10_", lexing.TOKEN_ILLEGAL, "10_", "Underscores in integer literals must separate digits."},
+		{"0x_1", lexing.TOKEN_ILLEGAL, "0x_1", "Underscores in integer literals must separate digits."},
+		{"0x", lexing.TOKEN_ILLEGAL, "0x", "The hexadecimal literal has no digits."},
+		{"0b102", lexing.TOKEN_ILLEGAL, "0b102", "Invalid digit '2' in binary literal."},
+		{"0o8", lexing.TOKEN_ILLEGAL, "0o8", "Invalid digit '8' in octal literal."},
+		{"12abc", lexing.TOKEN_ILLEGAL, "12abc", "Invalid character 'a' in integer literal."},
+	}
+
+	for _, expectation := range expectations {
+		lexer := lexing.NewLexer(expectation.input)
+		token := lexer.Next()
+
+		if token.Type != expectation.tokenType ||
+			token.Literal != expectation.value ||
+			token.Message != expectation.message {
+			t.Fatalf(
+				"Expected token %q of type %d with message %q, found token %q of type %d with message %q.",
+				expectation.value,
+				expectation.tokenType,
+				expectation.message,
+				token.Literal,
+				token.Type,
+				token.Message,
+			)
+		}
+	}
+}
+
+func TestLexerTemplates(t *testing.T) {
+	content := `"total: ${sum({"a": 1}["a"])} of ${"nested ${n}"}!" "\${literal}"`
+
+	expectations := []struct {
+		tokenType lexing.TokenType
+		value     string
+	}{
+		{lexing.TOKEN_TEMPLATE_HEAD, "total: "},
+		{lexing.TOKEN_IDENTIFIER, "sum"},
+		{lexing.TOKEN_OPEN_PAREN, "("},
+		{lexing.TOKEN_OPEN_BRACE, "{"},
+		{lexing.TOKEN_STRING, "a"},
+		{lexing.TOKEN_COLON, ":"},
+		{lexing.TOKEN_INTEGER, "1"},
+		{lexing.TOKEN_CLOSE_BRACE, "}"},
+		{lexing.TOKEN_OPEN_BRACKET, "["},
+		{lexing.TOKEN_STRING, "a"},
+		{lexing.TOKEN_CLOSE_BRACKET, "]"},
+		{lexing.TOKEN_CLOSE_PAREN, ")"},
+		{lexing.TOKEN_TEMPLATE_MIDDLE, " of "},
+		{lexing.TOKEN_TEMPLATE_HEAD, "nested "},
+		{lexing.TOKEN_IDENTIFIER, "n"},
+		{lexing.TOKEN_TEMPLATE_TAIL, ""},
+		{lexing.TOKEN_TEMPLATE_TAIL, "!"},
+		{lexing.TOKEN_STRING, "${literal}"},
+		{lexing.TOKEN_EOF, "\x00"},
+	}
+
+	lexer := lexing.NewLexer(content)
+
+	for index, expectation := range expectations {
+		token := lexer.Next()
+
+		if token.Type != expectation.tokenType ||
+			token.Literal != expectation.value {
+			t.Fatalf(
+				"[%d] Expected token %q of type %d, found token %q of type %d.",
+				index,
+				expectation.value,
+				expectation.tokenType,
+				token.Literal,
+				token.Type,
+			)
+		}
+	}
+}
+
+func TestLexerOperators(t *testing.T) {
+	content := "&& || % ** * & | ^ ~ << <= < >> >= > ? ?. ?? ... null while for in break continue const"
+
+	expectations := []struct {
+		tokenType lexing.TokenType
+		value     string
+	}{
+		{lexing.TOKEN_AND, "&&"},
+		{lexing.TOKEN_OR, "||"},
+		{lexing.TOKEN_PERCENT, "%"},
+		{lexing.TOKEN_POWER, "**"},
+		{lexing.TOKEN_ASTERISK, "*"},
+		{lexing.TOKEN_AMPERSAND, "&"},
+		{lexing.TOKEN_PIPE, "|"},
+		{lexing.TOKEN_CARET, "^"},
+		{lexing.TOKEN_TILDE, "~"},
+		{lexing.TOKEN_SHIFT_LEFT, "<<"},
+		{lexing.TOKEN_LESS_OR_EQUALS, "<="},
+		{lexing.TOKEN_LESS, "<"},
+		{lexing.TOKEN_SHIFT_RIGHT, ">>"},
+		{lexing.TOKEN_GREATER_OR_EQUALS, ">="},
+		{lexing.TOKEN_GREATER, ">"},
+		{lexing.TOKEN_QUESTION, "?"},
+		{lexing.TOKEN_QUESTION_DOT, "?."},
+		{lexing.TOKEN_COALESCE, "??"},
+		{lexing.TOKEN_ELLIPSIS, "..."},
+		{lexing.TOKEN_NULL, "null"},
+		{lexing.TOKEN_WHILE, "while"},
+		{lexing.TOKEN_FOR, "for"},
+		{lexing.TOKEN_IN, "in"},
+		{lexing.TOKEN_BREAK, "break"},
+		{lexing.TOKEN_CONTINUE, "continue"},
+		{lexing.TOKEN_CONST, "const"},
+		{lexing.TOKEN_EOF, "\x00"},
+	}
+
+	lexer := lexing.NewLexer(content)
+
+	for index, expectation := range expectations {
+		token := lexer.Next()
+
+		if token.Type != expectation.tokenType ||
+			token.Literal != expectation.value {
+			t.Fatalf(
+				"[%d] Expected token %q of type %d, found token %q of type %d.",
+				index,
+				expectation.value,
+				expectation.tokenType,
+				token.Literal,
+				token.Type,
+			)
+		}
+	}
+}
+
+func FuzzLexer(f *testing.F) {
+	f.Add("let five = 5; let add = fn(x, y) { x + y; };")
+	f.Add("\"a\\n${b + \"${c}\"}\" `raw` /* block */ // line")
+	f.Add("0x_ff 0b102 1__0 99999999999999999999 @ \xff")
+	f.Add("a?.b ?? c ? d : e && f || g ** h << i >> j")
+
+	f.Fuzz(func(t *testing.T, content string) {
+		lexer := lexing.NewLexer(content)
+
+		// every token but the end of file consumes at least one byte
+		offset := 0
+		for count := 0; count <= len(content)+1; count++ {
+			token := lexer.Next()
+			if token.Span.Start.Offset < offset ||
+				token.Span.End.Offset < token.Span.Start.Offset {
+				t.Fatalf(
+					"Token %q has span %d-%d after offset %d.",
+					token.Literal,
+					token.Span.Start.Offset,
+					token.Span.End.Offset,
+					offset,
+				)
+			}
+			if token.Type == lexing.TOKEN_ILLEGAL && token.Message == "" {
+				t.Fatalf("Illegal token %q has no message.", token.Literal)
+			}
+			if token.Type == lexing.TOKEN_EOF {
+				return
+			}
+			offset = token.Span.End.Offset
+		}
+		t.Fatalf("Lexer did not reach the end of file.")
+	})
+}
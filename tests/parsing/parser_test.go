@@ -3,7 +3,9 @@ package parsing_test
 import (
 	"monkey/lexing"
 	"monkey/parsing"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestParseExpressionStatement(t *testing.T) {
@@ -36,6 +38,49 @@ func TestParseExpressionStatement(t *testing.T) {
 		{"a = 2 + 2;", "a = (2 + 2);"},
 		{"array[2] = 4;", "array[2] = 4;"},
 		{"[1, 2, 3][2] = 24;", "[1, 2, 3][2] = 24;"},
+		{`"a\"b\\c\n";`, `"a\"b\\c\n";`},
+		{"`raw\\n\nstring`;", `"raw\\n\nstring";`},
+		{`"\u{1F600}";`, `"😀";`},
+		{"0xff + 0o17 + 0b11 + 1_000;", "(((255 + 15) + 3) + 1000);"},
+		{"1e6 + 2.5e3 + 1.0 + 0e99999999999 + 12_300e-2;", "((((1000000 + 2500) + 1) + 0) + 123);"},
+		{"9223372036854775807;", "9223372036854775807;"},
+		{`"total: ${sum(xs) + 1} of ${"${n}"}\n";`, `"total: ${(sum(xs) + 1)} of ${"${n}"}\n";`},
+		{`"${a}${b}";`, `"${a}${b}";`},
+		{"a || b && c == d;", "(a || (b && (c == d)));"},
+		{"a && b || c && !d;", "((a && b) || (c && (!d)));"},
+		{"a = b || c;", "a = (b || c);"},
+		{"a % b * c;", "((a % b) * c);"},
+		{"2 ** 3 ** 2;", "(2 ** (3 ** 2));"},
+		{"-2 ** 2;", "(-(2 ** 2));"},
+		{"2 * 3 ** 2;", "(2 * (3 ** 2));"},
+		{"a | b ^ c & d;", "(a | (b ^ (c & d)));"},
+		{"a & 1 == 0;", "((a & 1) == 0);"},
+		{"1 << 2 + 3;", "(1 << (2 + 3));"},
+		{"a >> 1 < b | c;", "((a >> 1) < (b | c));"},
+		{"~a & b;", "((~a) & b);"},
+		{"a ? b : c;", "(a ? b : c);"},
+		{"a[1:2];", "a[1:2];"},
+		{"a[:n - 1];", "a[:(n - 1)];"},
+		{"a[-2:];", "a[(-2):];"},
+		{"a[:];", "a[:];"},
+		{"a?.[1:2][0];", "a?.[1:2][0];"},
+		{"a[b ? 1 : 2];", "a[(b ? 1 : 2)];"},
+		{"fn (a, b = 1 + 2, ...rest) { a; };", "fn (a, b = (1 + 2), ...rest) { a; };"},
+		{"f(a, ...xs, ...g(1));", "f(a, ...xs, ...g(1));"},
+		{"[1, ...xs];", "[1, ...xs];"},
+		{"{...h, \"a\": 1};", "{...h, \"a\": 1};"},
+		{"null;", "null;"},
+		{"a?.b?.[c + 1];", "a?.b?.[(c + 1)];"},
+		{"a?.b[0]?.c(1);", "a?.b[0]?.c(1);"},
+		{"a ?? b ?? c;", "((a ?? b) ?? c);"},
+		{"a ?? b || c;", "(a ?? (b || c));"},
+		{"a ?? b ? c : d;", "((a ?? b) ? c : d);"},
+		{"a ? b : c ? d : e;", "(a ? b : (c ? d : e));"},
+		{"a || b ? c + 1 : d;", "((a || b) ? (c + 1) : d);"},
+		{"a = b ? c : d;", "a = (b ? c : d);"},
+		{"a ? b ? c : d : e;", "(a ? (b ? c : d) : e);"},
+		{"if (a) { 1; } else if (b) { 2; } else { 3; };", "if (a) { 1; } else if (b) { 2; } else { 3; };"},
+		{"if (a) { 1; } else if (b) { 2; } else if (c) { 3; };", "if (a) { 1; } else if (b) { 2; } else if (c) { 3; };"},
 	}
 
 	for _, expectation := range expectations {
@@ -71,6 +116,13 @@ func TestParseLetStatement(t *testing.T) {
 		{"let b = true;", "let b = true;"},
 		{"let c = \"Hello, World\";", "let c = \"Hello, World\";"},
 		{"let d;", "let d;"},
+		{"let [a, b, ...rest] = xs;", "let [a, b, ...rest] = xs;"},
+		{"let [] = xs;", "let [] = xs;"},
+		{"let [...all] = f(1);", "let [...all] = f(1);"},
+		{"let {name, age: years} = person;", "let {name, age: years} = person;"},
+		{"let {\"first name\": first, last,} = person;", "let {\"first name\": first, last} = person;"},
+		{"const limit = 10;", "const limit = 10;"},
+		{"const [x, y] = point;", "const [x, y] = point;"},
 	}
 
 	for _, expectation := range expectations {
@@ -132,24 +184,99 @@ func TestParseReturnStatement(t *testing.T) {
 	}
 }
 
+func TestParseLoops(t *testing.T) {
+	expectations := []struct {
+		input  string
+		output string
+	}{
+		{"while (i < 10) { i = i + 1; }", "while ((i < 10)) { i = (i + 1); }"},
+		{"while (true) { break; };", "while (true) { break; }"},
+		{"for (x in [1, 2]) { if (x == 1) { continue; }; puts(x); }", "for (x in [1, 2]) { if ((x == 1)) { continue; }; puts(x); }"},
+		{"for (a in b) { for (c in d) { break; } continue; }", "for (a in b) { for (c in d) { break; } continue; }"},
+	}
+
+	for _, expectation := range expectations {
+		lexer := lexing.NewLexer(expectation.input)
+		parser := parsing.NewParser(lexer)
+		ast := parser.Parse()
+
+		if parser.HasErrors() {
+			for _, error := range parser.GetErrors() {
+				t.Log(error)
+			}
+			t.FailNow()
+		}
+
+		output := ast.String()
+
+		if output != expectation.output {
+			t.Fatalf(
+				"Expected: %q\nGot: %q",
+				expectation.output,
+				output,
+			)
+		}
+	}
+}
+
 func TestParserErrors(t *testing.T) {
 	expectations := []struct {
 		input string
 		error string
 	}{
-		{"-1", `Expected token of type semicolon. Found token "\x00" of type eof.`},
-		{"myfunction(4; 5);", `Expected token of type comma. Found token ";" of type semicolon.`},
-		{"myfunction(4, 5};", `Expected token of type comma. Found token "}" of type close brace.`},
-		{"array[4};", `Expected token of type close bracket. Found token "}" of type close brace.`},
-		{"[4; 5];", `Expected token of type comma. Found token ";" of type semicolon.`},
-		{"[4, 5};", `Expected token of type comma. Found token "}" of type close brace.`},
-		{"{4; 5};", `Expected token of type colon. Found token ";" of type semicolon.`},
-		{"{4: 5];", `Expected token of type comma. Found token "]" of type close bracket.`},
-		{"fn (2) {};", `Expected token of type identifier. Found token "2" of type integer.`},
-		{"if true {};", `Expected token of type open paren. Found token "true" of type true.`},
-		{"if (true) 2;", `Expected token of type open brace. Found token "2" of type integer.`},
-		{"if (true) { 2; } else false;", `Expected token of type open brace. Found token "false" of type false.`},
-		{"let a =;", `Expected expression. Found token ";" of type semicolon.`},
+		{"-1", `1:3: Expected token of type semicolon. Found token "\x00" of type eof.`},
+		{"myfunction(4; 5);", `1:13: Expected token of type comma. Found token ";" of type semicolon.`},
+		{"myfunction(4, 5};", `1:16: Expected token of type comma. Found token "}" of type close brace.`},
+		{"array[4};", `1:8: Expected token of type close bracket. Found token "}" of type close brace.`},
+		{"[4; 5];", `1:3: Expected token of type comma. Found token ";" of type semicolon.`},
+		{"[4, 5};", `1:6: Expected token of type comma. Found token "}" of type close brace.`},
+		{"{4; 5};", `1:3: Expected token of type colon. Found token ";" of type semicolon.`},
+		{"{4: 5];", `1:6: Expected token of type comma. Found token "]" of type close bracket.`},
+		{"fn (2) {};", `1:5: Expected token of type identifier. Found token "2" of type integer.`},
+		{"if true {};", `1:4: Expected token of type open paren. Found token "true" of type true.`},
+		{"if (true) 2;", `1:11: Expected token of type open brace. Found token "2" of type integer.`},
+		{"if (true) { 2; } else false;", `1:23: Expected token of type open brace. Found token "false" of type false.`},
+		{"let a =;", `1:8: Expected expression. Found token ";" of type semicolon.`},
+		{`"bad \q";`, `1:1: Invalid escape sequence "\q".`},
+		{`let a = "never closed;`, `1:9: Unterminated string literal.`},
+		{"let a = 1 @ 2;", `1:11: Unexpected character "@".`},
+		{"99999999999999999999;", "1:1: Integer literal 99999999999999999999 is out of range."},
+		{"1e19;", "1:1: Integer literal 1e19 is out of range."},
+		{"1e99999999999;", "1:1: Integer literal 1e99999999999 is out of range."},
+		{"1.5;", "1:1: Literal 1.5 is not an integer, there is no float type."},
+		{"let a = 15e-1;", "1:9: Literal 15e-1 is not an integer, there is no float type."},
+		{"1e-99999999999;", "1:1: Literal 1e-99999999999 is not an integer, there is no float type."},
+		{`"value: ${}";`, `1:11: Expected expression. Found token "" of type template tail.`},
+		{`"value: ${1 2}";`, `1:13: Expected token of type template middle, template tail. Found token "2" of type integer.`},
+		{"a?.1;", `1:4: Expected token of type identifier. Found token "1" of type integer.`},
+		{"a?.[1;", `1:6: Expected token of type close bracket. Found token ";" of type semicolon.`},
+		{"a[1:2;", `1:6: Expected token of type close bracket. Found token ";" of type semicolon.`},
+		{"a[1:2:3];", `1:6: Expected token of type close bracket. Found token ":" of type colon.`},
+		{"a ? b;", `1:6: Expected token of type colon. Found token ";" of type semicolon.`},
+		{"if (a) { 1; } else if b { 2; };", `1:23: Expected token of type open paren. Found token "b" of type identifier.`},
+		{"let x = );", `1:9: Expected expression. Found token ")" of type close paren.`},
+		{"+;", `1:1: Expected expression. Found token "+" of type plus.`},
+		{"1 + ;", `1:5: Expected expression. Found token ";" of type semicolon.`},
+		{"f(1, ];", `1:6: Expected expression. Found token "]" of type close bracket.`},
+		{"(1 + 2;", `1:7: Expected token of type close paren. Found token ";" of type semicolon.`},
+		{"let 1 = 2;", `1:5: Expected token of type identifier. Found token "1" of type integer.`},
+		{"fn x {};", `1:4: Expected token of type open paren. Found token "x" of type identifier.`},
+		{"let [a, ...b, c] = xs;", `1:13: Expected token of type close bracket. Found token "," of type comma.`},
+		{"let [a, 1] = xs;", `1:9: Expected token of type identifier. Found token "1" of type integer.`},
+		{"let [a];", `1:8: Expected token of type assign. Found token ";" of type semicolon.`},
+		{"const a;", `1:8: Expected token of type assign. Found token ";" of type semicolon.`},
+		{"let {\"a\"} = h;", `1:9: Expected token of type colon. Found token "}" of type close brace.`},
+		{"let {a: 1} = h;", `1:9: Expected token of type identifier. Found token "1" of type integer.`},
+		{"let a = 1..2;", `1:10: Unexpected character ".".`},
+		{"fn (...a, b) {};", `1:9: Expected token of type close paren. Found token "," of type comma.`},
+		{"fn (...a = 1) {};", `1:10: Expected token of type close paren. Found token "=" of type assign.`},
+		{"fn (a = ) {};", `1:9: Expected expression. Found token ")" of type close paren.`},
+		{"let a = ...xs;", `1:9: Expected expression. Found token "..." of type ellipsis.`},
+		{"break;", "1:1: Unexpected break outside of a loop."},
+		{"while (true) { fn () { continue; }; }", "1:24: Unexpected continue outside of a loop."},
+		{"for (1 in xs) {}", "1:6: Expected token of type identifier. Found token \"1\" of type integer."},
+		{"for (x of xs) {}", "1:8: Expected token of type in. Found token \"of\" of type identifier."},
+		{"let a = 0x1_0000_0000_0000_0000;", "1:9: Integer literal 0x1_0000_0000_0000_0000 is out of range."},
 	}
 
 	for _, expectation := range expectations {
@@ -168,3 +295,244 @@ func TestParserErrors(t *testing.T) {
 
 	}
 }
+
+func TestParserRecovery(t *testing.T) {
+	content := "let a = [1 2;\n" +
+		"let b = {\"a\": 1 2};\n" +
+		"if (b) { let c = ; puts(c); };\n" +
+		"puts(1, 2 @);\n" +
+		"} let d = 99999999999999999999;\n" +
+		"let e = 1 let f = 2;\n" +
+		"e;"
+
+	expectations := []string{
+		`1:12: Expected token of type comma. Found token "2" of type integer.`,
+		`2:17: Expected token of type comma. Found token "2" of type integer.`,
+		`3:18: Expected expression. Found token ";" of type semicolon.`,
+		`4:11: Unexpected character "@".`,
+		`5:1: Expected token of type eof. Found token "}" of type close brace.`,
+		`5:11: Integer literal 99999999999999999999 is out of range.`,
+		`6:11: Expected token of type semicolon. Found token "let" of type let.`,
+	}
+
+	lexer := lexing.NewLexer(content)
+	parser := parsing.NewParser(lexer)
+	compound := parser.Parse()
+
+	errors := parser.GetErrors()
+	if len(errors) != len(expectations) {
+		t.Fatalf("Expected %d errors, got %d: %q", len(expectations), len(errors), errors)
+	}
+	for index, expectation := range expectations {
+		if expectation != errors[index] {
+			t.Fatalf("Expected: %q\nGot: %q", expectation, errors[index])
+		}
+	}
+
+	last := compound.Statements[len(compound.Statements)-1]
+	if last.String() != "e;" {
+		t.Fatalf("Expected the last statement to be %q, got %q.", "e;", last.String())
+	}
+}
+
+func TestParserRecoveryIllegalTokens(t *testing.T) {
+	expectations := []struct {
+		input string
+		error string
+	}{
+		{"let a = @;", `1:9: Unexpected character "@".`},
+		{"let a = 1 @ 2;", `1:11: Unexpected character "@".`},
+		{"let a = 0b102;", `1:9: Invalid digit '2' in binary literal.`},
+		{`let x = "\q";`, `1:9: Invalid escape sequence "\q".`},
+		{"[1, @, 3];", `1:5: Unexpected character "@".`},
+	}
+
+	for _, expectation := range expectations {
+		lexer := lexing.NewLexer(expectation.input + " let z = 1;")
+		parser := parsing.NewParser(lexer)
+		compound := parser.Parse()
+
+		errors := parser.GetErrors()
+		if len(errors) != 1 {
+			t.Fatalf("Expected 1 error for %q, got %d: %q", expectation.input, len(errors), errors)
+		}
+		if errors[0] != expectation.error {
+			t.Fatalf("Expected: %q\nGot: %q", expectation.error, errors[0])
+		}
+
+		last := compound.Statements[len(compound.Statements)-1]
+		if last.String() != "let z = 1;" {
+			t.Fatalf("Expected the last statement to be %q, got %q.", "let z = 1;", last.String())
+		}
+	}
+}
+
+func TestParserDiagnostics(t *testing.T) {
+	lexer := lexing.NewLexer("let a = [1 2];")
+	parser := parsing.NewParser(lexer)
+	_ = parser.Parse()
+
+	diagnostics := parser.GetDiagnostics()
+	if len(diagnostics) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %d.", len(diagnostics))
+	}
+
+	diagnostic := diagnostics[0]
+	if diagnostic.Severity != parsing.SEVERITY_ERROR {
+		t.Fatalf("Expected severity error, got %s.", parsing.SeverityToString(diagnostic.Severity))
+	}
+	if diagnostic.Code != parsing.DIAGNOSTIC_UNEXPECTED_TOKEN {
+		t.Fatalf("Expected code unexpected token, got %s.", parsing.DiagnosticCodeToString(diagnostic.Code))
+	}
+	if len(diagnostic.Expected) != 1 || diagnostic.Expected[0] != lexing.TOKEN_COMMA {
+		t.Fatalf("Expected a comma to be expected, got %v.", diagnostic.Expected)
+	}
+	if diagnostic.Found.Type != lexing.TOKEN_INTEGER || diagnostic.Found.Literal != "2" {
+		t.Fatalf("Expected to find integer 2, got %q.", diagnostic.Found.Literal)
+	}
+	if diagnostic.Span.Start.Offset != 11 || diagnostic.Span.End.Offset != 12 {
+		t.Fatalf("Expected span 11-12, got %d-%d.", diagnostic.Span.Start.Offset, diagnostic.Span.End.Offset)
+	}
+}
+
+func TestParserRegister(t *testing.T) {
+	contains := func(parser *parsing.Parser, left parsing.AstExpression) parsing.AstExpression {
+		infixExpression := &parsing.AstInfixExpression{
+			Token:    parser.Current(),
+			Left:     left,
+			Operator: parser.Current().Literal,
+		}
+		precedence := parser.Precedence(parser.Current().Type)
+		parser.Advance()
+		infixExpression.Right = parser.ParseExpression(precedence)
+		infixExpression.Span = parser.SpanFrom(left.GetSpan().Start)
+		return infixExpression
+	}
+	length := func(parser *parsing.Parser) parsing.AstExpression {
+		prefixExpression := &parsing.AstPrefixExpression{
+			Token:    parser.Current(),
+			Operator: parser.Current().Literal,
+		}
+		parser.Advance()
+		prefixExpression.Right = parser.ParseExpression(parsing.PRECEDENCE_PREFIX)
+		prefixExpression.Span = parser.SpanFrom(prefixExpression.Token.Span.Start)
+		return prefixExpression
+	}
+
+	expectations := []struct {
+		input  string
+		output string
+	}{
+		{"a in b == c;", "((a in b) == c);"},
+		{"1 + 2 in xs && ok;", "(((1 + 2) in xs) && ok);"},
+		{"^xs + 1;", "((^xs) + 1);"},
+		{"a ^ b;", "(a ^ b);"},
+		{"for (x in xs) { x in ys; }", "for (x in xs) { (x in ys); }"},
+	}
+
+	for _, expectation := range expectations {
+		lexer := lexing.NewLexer(expectation.input)
+		parser := parsing.NewParser(lexer)
+		parser.RegisterInfix(lexing.TOKEN_IN, parsing.PRECEDENCE_EQUALS, contains)
+		parser.RegisterPrefix(lexing.TOKEN_CARET, length)
+		compound := parser.Parse()
+
+		if parser.HasErrors() {
+			t.Fatalf("Unexpected errors for %q: %q", expectation.input, parser.GetErrors())
+		}
+		if compound.String() != expectation.output {
+			t.Fatalf("Expected: %q\nGot: %q", expectation.output, compound.String())
+		}
+	}
+
+	parser := parsing.NewParser(lexing.NewLexer("a in b;"))
+	_ = parser.Parse()
+	if !parser.HasErrors() {
+		t.Fatalf("Expected registrations to only apply to their parser.")
+	}
+}
+
+func TestParseSpans(t *testing.T) {
+	content := "let a = [1, 2];\nadd(a[0],\n    3 * 4);"
+
+	lexer := lexing.NewFileLexer("main.mk", content)
+	parser := parsing.NewParser(lexer)
+	ast := parser.Parse()
+
+	if parser.HasErrors() {
+		for _, error := range parser.GetErrors() {
+			t.Log(error)
+		}
+		t.FailNow()
+	}
+
+	letStatement := ast.Statements[0].(*parsing.AstLetStatement)
+	call := ast.Statements[1].(*parsing.AstExpressionStatement).Expression.(*parsing.AstFunctionCall)
+	product := call.Arguments[1]
+
+	expectations := []struct {
+		node  parsing.AstNode
+		start string
+		end   string
+	}{
+		{ast, "main.mk:1:1", "main.mk:3:12"},
+		{letStatement, "main.mk:1:1", "main.mk:1:16"},
+		{letStatement.Value, "main.mk:1:9", "main.mk:1:15"},
+		{call, "main.mk:2:1", "main.mk:3:11"},
+		{call.Arguments[0], "main.mk:2:5", "main.mk:2:9"},
+		{product, "main.mk:3:5", "main.mk:3:10"},
+	}
+
+	for _, expectation := range expectations {
+		span := expectation.node.GetSpan()
+
+		if span.Start.String() != expectation.start ||
+			span.End.String() != expectation.end {
+			t.Fatalf(
+				"Expected %q to span %s-%s, got %s-%s.",
+				expectation.node.String(),
+				expectation.start,
+				expectation.end,
+				span.Start,
+				span.End,
+			)
+		}
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	reader := iotest.OneByteReader(strings.NewReader("let a = [1, 2];\nadd(a[0], 3 * 4);"))
+	lexer := lexing.NewReaderLexer("", reader)
+	parser := parsing.NewParser(lexer)
+	ast := parser.Parse()
+
+	if parser.HasErrors() {
+		for _, error := range parser.GetErrors() {
+			t.Log(error)
+		}
+		t.FailNow()
+	}
+
+	expected := "let a = [1, 2]; add(a[0], (3 * 4));"
+	if ast.String() != expected {
+		t.Fatalf("Expected: %q\nGot: %q", expected, ast.String())
+	}
+}
+
+func FuzzParser(f *testing.F) {
+	f.Add("let a = [1, 2]; let b = {\"a\": fn (x) { return x * 2; }}; b[\"a\"](a[0]);")
+	f.Add("if (a) { 1; } else if (b) { 2; } else { 3; }; a ? b : c ?? d?.e?.[f];")
+	f.Add("while (true) { for (x in xs) { break; continue; } } \"${a}${b}\";")
+	f.Add("let x = ); +; fn (1) {}; } ] ) let = ; return")
+
+	f.Fuzz(func(t *testing.T, content string) {
+		lexer := lexing.NewLexer(content)
+		parser := parsing.NewParser(lexer)
+		compound := parser.Parse()
+
+		text := compound.String()
+		if strings.Contains(text, "<bad expression>") && !parser.HasErrors() {
+			t.Fatalf("Bad expression without a diagnostic in %q.", text)
+		}
+	})
+}